@@ -21,7 +21,9 @@ import (
 	"fmt"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/versions"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 )
 
@@ -92,11 +94,22 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	// dumping a replacement configuration doesn't require to be logged in
+	var connection *sdk.Connection
+	var err error
+	if !args.dump || !args.replace {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
@@ -118,11 +131,11 @@ func run(cmd *cobra.Command, argv []string) error {
 	// consolidate version blocks
 	var currentVersionBlocks = []string{}
 	if !args.replace {
-		currentVersionBlocks, err = be.ListBlockedVersionExpressions(args.organizationId)
+		currentVersionBlocks, err = be.ListBlockedVersionExpressions(cmd.Context(), args.organizationId)
 		if err != nil {
 			return err
 		}
 	}
 	blockExpressions := versions.ConsolidateVersionBlocks(currentVersionBlocks, blocking, unblocking)
-	return be.ApplyBlockedVersionExpressions(args.organizationId, blockExpressions, args.dump, args.dryRun)
+	return be.ApplyBlockedVersionExpressions(cmd.Context(), args.organizationId, blockExpressions, args.dump, args.dryRun)
 }
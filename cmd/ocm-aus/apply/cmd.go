@@ -18,10 +18,12 @@ package apply
 
 import (
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/blockedversions"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/defaults"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/gateagreement"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/inheritance"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/policy"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/sector"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply/templates"
 	"github.com/spf13/cobra"
 )
 
@@ -41,4 +43,6 @@ func init() {
 	Cmd.AddCommand(blockedversions.Cmd)
 	Cmd.AddCommand(inheritance.Cmd)
 	Cmd.AddCommand(gateagreement.Cmd)
+	Cmd.AddCommand(templates.Cmd)
+	Cmd.AddCommand(defaults.Cmd)
 }
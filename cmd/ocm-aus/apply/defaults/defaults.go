@@ -0,0 +1,217 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/schedule"
+	"github.com/app-sre/aus-cli/pkg/utils"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	organizationId string
+	schedule       string
+	soakDays       int
+	sector         string
+	mutexes        []string
+	unset          []string
+	replace        bool
+
+	dryRun bool
+	dump   bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "policy-defaults [flags] [-]",
+	Short: "Create or update the policy defaults of an organization",
+	Long: "Create or update the policy defaults of an organization.\n" +
+		"\n" +
+		"Cluster upgrade policies inherit the default schedule, soak days, sector and mutexes if they don't set them.\n" +
+		"The defaults are either defined by flags or are read from stdin if the - arg is present. \n" +
+		"To learn about the stdin format, run this command with flags and use --dump.\n",
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to manage",
+	)
+	schedulePresets := strings.Join(schedule.SupportedSchedulePresets(), ", ")
+	flags.StringVarP(
+		&args.schedule,
+		"schedule",
+		"s",
+		"",
+		fmt.Sprintf("The default cron expression or schedule preset (%s)", schedulePresets),
+	)
+	flags.IntVarP(
+		&args.soakDays,
+		"soak-days",
+		"d",
+		0,
+		"The default number of days to wait before upgrading a cluster.",
+	)
+	flags.StringVar(
+		&args.sector,
+		"sector",
+		"",
+		"The default sector of the clusters.",
+	)
+	flags.StringArrayVarP(
+		&args.mutexes,
+		"mutex",
+		"m",
+		[]string{},
+		"The default mutexes a cluster must hold before it can start an upgrade. Can be specified multiple times.",
+	)
+	flags.StringArrayVar(
+		&args.unset,
+		"unset",
+		[]string{},
+		fmt.Sprintf("Remove a default (%s). Can be specified multiple times.", strings.Join(policy.DefaultableFields, ", ")),
+	)
+	flags.BoolVar(
+		&args.replace,
+		"replace",
+		false,
+		"Replace all policy defaults with the provided ones. "+
+			"Otherwise, the provided defaults are merged with the existing ones.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"",
+	)
+	flags.BoolVar(
+		&args.dump,
+		"dump",
+		false,
+		"Dumps the policy defaults to stdout and exits without applying them.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	for _, field := range args.unset {
+		if !utils.StringInArray(policy.DefaultableFields, field) {
+			return fmt.Errorf("unknown policy default %s, supported are %s", field, strings.Join(policy.DefaultableFields, ", "))
+		}
+	}
+
+	// dumping a replacement configuration doesn't require to be logged in
+	var connection *sdk.Connection
+	var err error
+	replace := args.replace || (len(argv) > 0 && argv[0] == "-")
+	if !args.dump || !replace {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	var defaults policy.PolicyDefaults
+	if len(argv) > 0 && argv[0] == "-" {
+		defaults, err = policy.NewPolicyDefaultsFromReader(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to decode input: %v", err)
+		}
+	} else {
+		if !anyDefaultFlag(cmd) && len(args.unset) == 0 {
+			return errors.New("none of the policy default flags were provided")
+		}
+		if !replace {
+			defaults, err = be.GetPolicyDefaults(cmd.Context(), args.organizationId)
+			if err != nil {
+				return err
+			}
+		}
+		defaults, err = updateDefaults(cmd, defaults)
+		if err != nil {
+			return err
+		}
+	}
+	if defaults.Schedule != "" {
+		defaults.Schedule, err = schedule.TranslateSchedule(defaults.Schedule)
+		if err != nil {
+			return err
+		}
+	}
+	return be.ApplyPolicyDefaults(cmd.Context(), args.organizationId, defaults, args.dump, args.dryRun)
+}
+
+func anyDefaultFlag(cmd *cobra.Command) bool {
+	for _, flag := range []string{"schedule", "soak-days", "sector", "mutex"} {
+		if cmd.Flags().Changed(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func updateDefaults(cmd *cobra.Command, defaults policy.PolicyDefaults) (policy.PolicyDefaults, error) {
+	flags := cmd.Flags()
+	if flags.Changed("schedule") {
+		defaults.Schedule = args.schedule
+	}
+	if flags.Changed("soak-days") {
+		soakDays := args.soakDays
+		defaults.SoakDays = &soakDays
+	}
+	if flags.Changed("sector") {
+		defaults.Sector = args.sector
+	}
+	if flags.Changed("mutex") {
+		defaults.Mutexes = args.mutexes
+	}
+	for _, field := range args.unset {
+		switch field {
+		case policy.ScheduleField:
+			defaults.Schedule = ""
+		case policy.SoakDaysField:
+			defaults.SoakDays = nil
+		case policy.SectorField:
+			defaults.Sector = ""
+		case policy.MutexesField:
+			defaults.Mutexes = nil
+		}
+	}
+	return defaults, defaults.Validate()
+}
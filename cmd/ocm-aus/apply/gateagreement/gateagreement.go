@@ -78,22 +78,25 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	ctx := cmd.Context()
 	connection, err := ocm.NewOCMConnection()
 	if err != nil {
 		return err
 	}
+	defer connection.Close()
+
 	organizationId := args.organizationId
 	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
+		organizationId, err = ocm.CurrentOrganizationId(ctx, connection)
 		if err != nil {
 			return err
 		}
 	}
 
-	cluster, err := clusters.GetClusterInfoByName(organizationId, args.clusterName, connection)
+	cluster, err := clusters.GetClusterInfoByName(ctx, organizationId, args.clusterName, connection)
 	if err != nil {
 		return err
 	}
-	_, err = clusters.AckAllGatesForYStream(cluster, args.version, connection, args.dryRun)
+	_, err = clusters.AckAllGatesForYStream(ctx, cluster, args.version, connection, args.dryRun)
 	return err
 }
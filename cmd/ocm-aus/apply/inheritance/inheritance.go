@@ -20,7 +20,9 @@ import (
 	"fmt"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/versiondata"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +30,8 @@ var args struct {
 	organizationId string
 	inherit        []string
 	publish        []string
+	removeInherit  []string
+	removePublish  []string
 	replace        bool
 
 	dryRun bool
@@ -40,7 +44,8 @@ var Cmd = &cobra.Command{
 	Long: "Create or update the cross-organization version data inheritance.\n" +
 		"\n" +
 		"The configuration is either defined by flags or are read from stdin in the - arg is present. \n" +
-		"If - is present, --inherit-from and --publish-to will be ignored.\n" +
+		"If - is present, --inherit-from, --publish-to, --remove-inherit-from and --remove-publish-to will be ignored.\n" +
+		"If both lists end up empty, the inheritance configuration is removed from the organization.\n" +
 		"To learn about the stdin format, run this command with flags and use --dump.\n",
 	RunE: run,
 }
@@ -60,7 +65,8 @@ func init() {
 		"inherit-from",
 		"i",
 		[]string{},
-		"A comma-separated list of organization IDs to inherit version data from. The listed organizations "+
+		"A comma-separated list of organization IDs to inherit version data from. The version data can be "+
+			"restricted to some workloads with an ORG:WORKLOAD[,WORKLOAD...] entry per organization. The listed organizations "+
 			"need to define a matching publish-to entry in their configuration for this to work. Otherwise AUS "+
 			"will not trigger any updates for the inheriting organization and will publish a service log "+
 			"on all affected clusters. The referenced organizations can be located in different OCM environments.",
@@ -70,10 +76,25 @@ func init() {
 		"publish-to",
 		"p",
 		[]string{},
-		"A comma-separated list of organization IDs to publish version data to. The listed organizations "+
+		"A comma-separated list of organization IDs to publish version data to. The version data can be "+
+			"restricted to some workloads with an ORG:WORKLOAD[,WORKLOAD...] entry per organization. The listed organizations "+
 			"need to define a matching inherit-from entry in their configuration for this to work. The "+
 			"referenced organizations can be located in different OCM environments.",
 	)
+	flags.StringArrayVar(
+		&args.removeInherit,
+		"remove-inherit-from",
+		[]string{},
+		"A comma-separated list of organization IDs to no longer inherit version data from. "+
+			"Their workload filters are removed as well.",
+	)
+	flags.StringArrayVar(
+		&args.removePublish,
+		"remove-publish-to",
+		[]string{},
+		"A comma-separated list of organization IDs to no longer publish version data to. "+
+			"Their workload filters are removed as well.",
+	)
 	flags.BoolVar(
 		&args.replace,
 		"replace",
@@ -96,36 +117,73 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	// dumping a replacement configuration doesn't require to be logged in
+	var connection *sdk.Connection
+	var err error
+	if !args.dump || !args.replace {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
 
-	var config versiondata.VersionDataInheritanceConfig
+	var config, removedConfig versiondata.VersionDataInheritanceConfig
 	if len(argv) > 0 && argv[0] == "-" {
 		config, err = versiondata.NewVersionDataInheritanceConfigFromReader(cmd.InOrStdin())
 		if err != nil {
 			return fmt.Errorf("failed to decode input: %v", err)
 		}
+		err = config.Validate()
+		if err != nil {
+			return err
+		}
 	} else {
-		config = versiondata.VersionDataInheritanceConfig{
-			InheritingFromOrgs: args.inherit,
-			PublishingToOrgs:   args.publish,
+		config, err = versiondata.NewVersionDataInheritanceConfigFromEntries(args.inherit, args.publish)
+		if err != nil {
+			return err
+		}
+		removedConfig, err = versiondata.NewRemovedVersionDataInheritanceConfigFromEntries(args.removeInherit, args.removePublish)
+		if err != nil {
+			return err
 		}
 	}
 
 	// consolidate configs
 	var currentConfig versiondata.VersionDataInheritanceConfig
 	if !args.replace {
-		currentConfig, err = be.GetVersionDataInheritanceConfiguration(args.organizationId)
+		currentConfig, err = be.GetVersionDataInheritanceConfiguration(cmd.Context(), args.organizationId)
+		if err != nil {
+			return err
+		}
+	}
+	consolidatedConfig := versiondata.ConsolidateVersionDataInheritanceConfig(currentConfig, config, removedConfig)
+
+	// workload filters must refer to workloads of the organization
+	if connection != nil && (len(consolidatedConfig.InheritWorkloads) > 0 || len(consolidatedConfig.PublishWorkloads) > 0) {
+		policies, err := be.ListPolicies(cmd.Context(), args.organizationId, false)
+		if err != nil {
+			return err
+		}
+		workloads := []string{}
+		for _, clusterInfo := range policies {
+			if clusterInfo.Policy != nil {
+				workloads = append(workloads, clusterInfo.Policy.Workloads...)
+			}
+		}
+		err = consolidatedConfig.ValidateWorkloads(workloads)
 		if err != nil {
 			return err
 		}
 	}
-	consolidatedConfig := versiondata.ConsolidateVersionDataInheritanceConfig(currentConfig, config)
-	return be.ApplyVersionDataInheritanceConfiguration(args.organizationId, consolidatedConfig, args.dump, args.dryRun)
+	return be.ApplyVersionDataInheritanceConfiguration(cmd.Context(), args.organizationId, consolidatedConfig, args.dump, args.dryRun)
 }
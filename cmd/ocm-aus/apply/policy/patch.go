@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/clusters"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/schedule"
+	"github.com/spf13/cobra"
+)
+
+// readPatches reads the merge patches from stdin or builds a single patch out of the given flags.
+func readPatches(cmd *cobra.Command, argv []string) ([]policy.PolicyPatch, error) {
+	var patches []policy.PolicyPatch
+	if len(argv) > 0 && argv[0] == "-" {
+		var err error
+		patches, err = policy.NewPolicyPatchesFromReader(cmd.InOrStdin())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode input: %v", err)
+		}
+	} else {
+		patch, err := patchFromFlags(cmd)
+		if err != nil {
+			return nil, err
+		}
+		patches = []policy.PolicyPatch{{Patch: patch}}
+	}
+
+	// the flags select the clusters of patches that don't name them
+	var selector map[string]string
+	if args.selector != "" {
+		var err error
+		selector, err = clusters.ParseSelector(args.selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range patches {
+		if patches[i].ClusterName == "" && len(patches[i].Selector) == 0 {
+			patches[i].ClusterName = args.clusterName
+			patches[i].Selector = selector
+		}
+		if patches[i].ClusterName == "" && len(patches[i].Selector) == 0 {
+			return nil, errors.New("a patch needs a cluster name or a selector")
+		}
+		if patches[i].ClusterName != "" && len(patches[i].Selector) > 0 {
+			return nil, errors.New("cluster name and selector of a patch are mutually exclusive")
+		}
+	}
+	return patches, nil
+}
+
+func patchFromFlags(cmd *cobra.Command) (map[string]interface{}, error) {
+	flags := cmd.Flags()
+	patch := map[string]interface{}{}
+	conditions := map[string]interface{}{}
+	if flags.Changed("schedule") {
+		clusterSchedule, err := schedule.TranslateSchedule(args.schedule)
+		if err != nil {
+			return nil, err
+		}
+		patch["schedule"] = clusterSchedule
+	}
+	if flags.Changed("workload") {
+		patch["workloads"] = args.workloads
+	}
+	if flags.Changed("soak-days") {
+		conditions["soak_days"] = args.soakDays
+	}
+	if flags.Changed("sector") {
+		conditions["sector"] = args.sector
+	}
+	if flags.Changed("mutex") {
+		conditions["mutexes"] = args.mutexes
+	}
+	if flags.Changed("blocked-versions") {
+		conditions["blocked_versions"] = args.blockedVersionExpressions
+	}
+	if flags.Changed("max-version") {
+		conditions["max_version"] = args.maxVersion
+	}
+	if flags.Changed("z-stream-only") {
+		conditions["z_stream_only"] = args.zStreamOnly
+	}
+	if flags.Changed("min-upgrade-interval-days") {
+		conditions["min_upgrade_interval_days"] = args.minUpgradeIntervalDays
+	}
+	if flags.Changed("require-approval") {
+		conditions["require_approval"] = args.requireApproval
+	}
+	if flags.Changed("canary") {
+		conditions["canary"] = args.canary
+	}
+	if flags.Changed("owner") {
+		patch["owner"] = args.owner
+	}
+	if flags.Changed("contact") {
+		patch["contact"] = args.contact
+	}
+	if flags.Changed("ticket") {
+		patch["ticket"] = args.ticket
+	}
+	if len(conditions) > 0 {
+		patch["conditions"] = conditions
+	}
+	if len(patch) == 0 {
+		return nil, errors.New("none of the policy flags were provided")
+	}
+	return patch, nil
+}
+
+// patchPolicies applies the patches to the current policies of the selected clusters. Patches are
+// applied in order, so later patches win over earlier ones for the same cluster.
+func patchPolicies(ctx context.Context, be backend.PolicyBackend, patches []policy.PolicyPatch) ([]policy.ClusterUpgradePolicy, error) {
+	current, err := be.ListPolicies(ctx, args.organizationId, true)
+	if err != nil {
+		return nil, err
+	}
+	clusterInfos := make([]*clusters.ClusterInfo, 0, len(current))
+	for _, clusterInfo := range current {
+		clusterInfos = append(clusterInfos, clusterInfo)
+	}
+
+	patched := make(map[string]policy.ClusterUpgradePolicy)
+	for _, patch := range patches {
+		var targets []*clusters.ClusterInfo
+		if patch.ClusterName != "" {
+			clusterInfo, ok := current[patch.ClusterName]
+			if !ok {
+				return nil, fmt.Errorf("cluster %s not found", patch.ClusterName)
+			}
+			targets = []*clusters.ClusterInfo{clusterInfo}
+		} else {
+			selector := clusters.NewSelector(patch.Selector)
+			if err := selector.Validate(); err != nil {
+				return nil, err
+			}
+			// selectors only patch existing policies
+			for _, clusterInfo := range clusters.SelectClusters(clusterInfos, selector) {
+				if clusterInfo.Policy.Validate() == nil {
+					targets = append(targets, clusterInfo)
+				}
+			}
+		}
+		for _, clusterInfo := range targets {
+			clusterName := clusterInfo.DisplayName()
+			clusterPolicy, ok := patched[clusterName]
+			if !ok {
+				// inherited values stay inherited unless the patch sets them
+				clusterPolicy = clusterInfo.Policy.WithoutInherited()
+				clusterPolicy.ClusterName = clusterName
+			}
+			clusterPolicy, err = clusterPolicy.MergePatch(patch.Patch)
+			if err != nil {
+				return nil, err
+			}
+			patched[clusterName] = clusterPolicy
+		}
+	}
+
+	policies := make([]policy.ClusterUpgradePolicy, 0, len(patched))
+	for _, clusterPolicy := range patched {
+		policies = append(policies, clusterPolicy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].ClusterName < policies[j].ClusterName
+	})
+	return policies, nil
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package policy
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/clusters"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/policy"
 	"github.com/app-sre/aus-cli/pkg/schedule"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 )
 
@@ -30,15 +34,31 @@ var args struct {
 	organizationId            string
 	clusterName               string
 	clusterUUID               string
+	selector                  string
 	schedule                  string
 	workloads                 []string
 	soakDays                  int
 	sector                    string
 	mutexes                   []string
 	blockedVersionExpressions []string
+	maxVersion                string
+	zStreamOnly               bool
+	minUpgradeIntervalDays    int
+	requireApproval           bool
+	canary                    bool
+	owner                     string
+	contact                   string
+	ticket                    string
+	templateFile              string
+	templates                 []string
+	patch                     bool
+	forceOwner                bool
 
-	dryRun bool
-	dump   bool
+	atomic            bool
+	concurrency       int
+	requestsPerSecond float64
+	dryRun            bool
+	dump              bool
 }
 
 var Cmd = &cobra.Command{
@@ -70,6 +90,13 @@ func init() {
 		"Name of the cluster to manage a policy for. "+
 			"This name needs to match the cluster name in OCM.",
 	)
+	flags.StringVar(
+		&args.selector,
+		"selector",
+		"",
+		"key=value,... Apply the policy to all clusters matching the selector instead of a single cluster. "+
+			"Supported keys are cloud_provider, region, product and channel_group, all other keys match subscription labels.",
+	)
 	schedulePresets := strings.Join(schedule.SupportedSchedulePresets(), ", ")
 	flags.StringVarP(
 		&args.schedule,
@@ -90,7 +117,7 @@ func init() {
 		"soak-days",
 		"d",
 		0,
-		"The number of days to wait before upgrading the cluster. Defaults to 0.",
+		"The number of days to wait before upgrading the cluster. Defaults to the organization default or 0.",
 	)
 	flags.StringVar(
 		&args.sector,
@@ -112,6 +139,100 @@ func init() {
 		[]string{},
 		"Blocked version expressions.",
 	)
+	flags.StringVar(
+		&args.maxVersion,
+		"max-version",
+		"",
+		"The highest minor version the cluster can be upgraded to, e.g. 4.15.",
+	)
+	flags.BoolVar(
+		&args.zStreamOnly,
+		"z-stream-only",
+		false,
+		"Only upgrade the cluster to patch versions of its current minor version.",
+	)
+	flags.IntVar(
+		&args.minUpgradeIntervalDays,
+		"min-upgrade-interval-days",
+		0,
+		"The minimum number of days between two upgrades of the cluster.",
+	)
+	flags.BoolVar(
+		&args.requireApproval,
+		"require-approval",
+		false,
+		"Only upgrade the cluster to versions approved with 'ocm aus approve'.",
+	)
+	flags.BoolVar(
+		&args.canary,
+		"canary",
+		false,
+		"Mark the cluster as the canary of its workloads. "+
+			"The other clusters of these workloads are only upgraded to versions the canary runs already. "+
+			"A workload with a canary must keep exactly one canary.",
+	)
+	flags.StringVar(
+		&args.owner,
+		"owner",
+		"",
+		"The team that owns the policy.",
+	)
+	flags.StringVar(
+		&args.contact,
+		"contact",
+		"",
+		"How to reach the owner of the policy, e.g. a chat channel.",
+	)
+	flags.StringVar(
+		&args.ticket,
+		"ticket",
+		"",
+		"The change ticket of the policy.",
+	)
+	flags.StringVar(
+		&args.templateFile,
+		"template-file",
+		"",
+		"Render the policy templates from this file and apply the resulting policies.",
+	)
+	flags.StringArrayVar(
+		&args.templates,
+		"template",
+		[]string{},
+		"Render the policy template with this name and apply the resulting policies. "+
+			"Templates are read from the organization unless --template-file is given. Can be specified multiple times.",
+	)
+	flags.BoolVar(
+		&args.patch,
+		"patch",
+		false,
+		"Merge the given flags into the current policy instead of replacing it. "+
+			"With -, JSON merge patches (RFC 7386) are read from stdin.",
+	)
+	flags.BoolVar(
+		&args.forceOwner,
+		"force-owner",
+		false,
+		"Change policies even if they are owned by another team.",
+	)
+	flags.BoolVar(
+		&args.atomic,
+		"atomic",
+		false,
+		"Apply all policies as a unit. If applying any policy fails, all changes are rolled back.",
+	)
+	flags.IntVar(
+		&args.concurrency,
+		"concurrency",
+		4,
+		"The number of policies to apply in parallel.",
+	)
+	flags.Float64Var(
+		&args.requestsPerSecond,
+		"rate",
+		10,
+		"The maximum number of OCM write requests per second. 0 disables the limit.",
+	)
 	flags.BoolVar(
 		&args.dryRun,
 		"dry-run",
@@ -122,52 +243,137 @@ func init() {
 		&args.dump,
 		"dump",
 		false,
-		"Dumps the policy configuration to stdout and exits without applying it.",
+		"Dumps the policy configuration to stdout and exits without applying it. "+
+			"The policies are only validated against the policy defaults of the organization if they are rendered from templates or patched.",
 	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
 	var policies []policy.ClusterUpgradePolicy
+	var patches []policy.PolicyPatch
 	var err error
-	if len(argv) > 0 && argv[0] == "-" {
+	fromTemplates := args.templateFile != "" || len(args.templates) > 0
+	if fromTemplates {
+		if len(argv) > 0 || args.clusterName != "" || args.selector != "" || args.patch {
+			return errors.New("templates can't be combined with policies from stdin, --cluster-name, --selector or --patch")
+		}
+	} else if args.patch {
+		patches, err = readPatches(cmd, argv)
+		if err != nil {
+			return err
+		}
+	} else if len(argv) > 0 && argv[0] == "-" {
 		policies, err = policy.NewClusterUpgradePolicyFromReader(cmd.InOrStdin())
 
 		if err != nil {
 			return fmt.Errorf("failed to decode input: %v", err)
 		}
 	} else {
-		schedule, err := schedule.TranslateSchedule(args.schedule)
-		if err != nil {
-			return err
+		// the schedule and soak days can be inherited from the organization defaults
+		var clusterSchedule string
+		if args.schedule != "" {
+			clusterSchedule, err = schedule.TranslateSchedule(args.schedule)
+			if err != nil {
+				return err
+			}
+		}
+		var soakDays *int
+		if cmd.Flags().Changed("soak-days") {
+			soakDays = &args.soakDays
 		}
-		policies = []policy.ClusterUpgradePolicy{
-			policy.NewClusterUpgradePolicy(
-				args.clusterName,
-				schedule,
-				args.workloads,
-				args.soakDays,
-				args.sector,
-				args.mutexes,
-				args.blockedVersionExpressions,
-			),
+		clusterPolicy := policy.NewClusterUpgradePolicy(
+			args.clusterName,
+			clusterSchedule,
+			args.workloads,
+			soakDays,
+			args.sector,
+			args.mutexes,
+			args.blockedVersionExpressions,
+			args.maxVersion,
+		)
+		clusterPolicy.Conditions.ZStreamOnly = args.zStreamOnly
+		clusterPolicy.Conditions.MinUpgradeIntervalDays = args.minUpgradeIntervalDays
+		clusterPolicy.Conditions.RequireApproval = args.requireApproval
+		clusterPolicy.Conditions.Canary = args.canary
+		clusterPolicy.Owner = args.owner
+		clusterPolicy.Contact = args.contact
+		clusterPolicy.Ticket = args.ticket
+		if args.selector != "" {
+			clusterPolicy.Selector, err = clusters.ParseSelector(args.selector)
+			if err != nil {
+				return err
+			}
 		}
+		policies = []policy.ClusterUpgradePolicy{clusterPolicy}
 	}
 	for _, pol := range policies {
-		err = pol.Validate()
+		err = pol.ValidateExplicit()
 		if err != nil {
 			return fmt.Errorf("invalid policy: %v", err)
 		}
 	}
 
+	// dumping the policies doesn't require to be logged in, unless they are rendered from templates
+	// or patched
+	var connection *sdk.Connection
+	if !args.dump || fromTemplates || args.patch {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
-	err = be.ApplyPolicies(args.organizationId, policies, args.dump, args.dryRun)
+	if fromTemplates {
+		templates, err := backend.LoadPolicyTemplates(cmd.Context(), be, args.organizationId, args.templateFile, args.templates)
+		if err != nil {
+			return err
+		}
+		policies, err = be.RenderPolicyTemplates(cmd.Context(), args.organizationId, templates)
+		if err != nil {
+			return err
+		}
+	}
+	if args.patch {
+		policies, err = patchPolicies(cmd.Context(), be, patches)
+		if err != nil {
+			return err
+		}
+	}
+	if args.dump && connection != nil {
+		defaults, err := be.GetPolicyDefaults(cmd.Context(), args.organizationId)
+		if err != nil {
+			return err
+		}
+		for _, pol := range policies {
+			if err := defaults.Merge(pol).Validate(); err != nil {
+				return fmt.Errorf("invalid policy for cluster %s: %v", pol.ClusterName, err)
+			}
+		}
+	}
+	var guard policy.OwnerGuard
+	if !args.dump {
+		guard, err = backend.NewOwnerGuard(args.forceOwner)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = be.ApplyPolicies(cmd.Context(), args.organizationId, policies, policy.ApplyOptions{
+		Dump:              args.dump,
+		DryRun:            args.dryRun,
+		Atomic:            args.atomic,
+		Concurrency:       args.concurrency,
+		RequestsPerSecond: args.requestsPerSecond,
+		OwnerGuard:        guard,
+	})
 	return err
 }
 
@@ -20,7 +20,9 @@ import (
 	"fmt"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/sectors"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 )
 
@@ -102,11 +104,21 @@ func run(cmd *cobra.Command, argv []string) error {
 	var sectorList []sectors.Sector
 	var err error
 
+	// dumping a replacement configuration doesn't require to be logged in
+	var connection *sdk.Connection
+	if !args.dump || !args.replace {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
@@ -137,7 +149,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	// consolidate dependencies
 	var currentSectors = []sectors.Sector{}
 	if !args.replace {
-		currentSectors, err = be.ListSectorConfiguration(args.organizationId)
+		currentSectors, err = be.ListSectorConfiguration(cmd.Context(), args.organizationId)
 		if err != nil {
 			return err
 		}
@@ -146,7 +158,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		currentSectors, adding, removing, sectorsMaxParallelUpgrades,
 	)
 
-	err = be.ApplySectorConfiguration(args.organizationId, sectorList, args.dump, args.dryRun)
+	err = be.ApplySectorConfiguration(cmd.Context(), args.organizationId, sectorList, args.dump, args.dryRun)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	organizationId string
+	replace        bool
+
+	dryRun bool
+	dump   bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "policy-templates -",
+	Short: "Create or update the policy templates of an organization",
+	Long: "Create or update the policy templates of an organization.\n" +
+		"\n" +
+		"The templates are read from stdin as a JSON list. Templates with the same name are replaced.\n" +
+		"Use 'ocm aus render' to check the policies the templates produce.\n",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to manage",
+	)
+	flags.BoolVar(
+		&args.replace,
+		"replace",
+		false,
+		"Replace all policy templates on the organization with the provided ones. "+
+			"Otherwise, the provided templates will be added to the existing ones.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"",
+	)
+	flags.BoolVar(
+		&args.dump,
+		"dump",
+		false,
+		"Dumps the policy templates to stdout and exits without applying them.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if argv[0] != "-" {
+		return errors.New("policy templates can only be read from stdin, use - as argument")
+	}
+	templates, err := policy.NewPolicyTemplatesFromReader(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to decode input: %v", err)
+	}
+
+	// dumping a replacement configuration doesn't require to be logged in
+	var connection *sdk.Connection
+	if !args.dump || !args.replace {
+		connection, err = ocm.NewOCMConnection()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+	}
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	if !args.replace {
+		current, err := be.ListPolicyTemplates(cmd.Context(), args.organizationId)
+		if err != nil {
+			return err
+		}
+		templates = mergeTemplates(current, templates)
+	}
+	policy.SortPolicyTemplates(templates)
+	return be.ApplyPolicyTemplates(cmd.Context(), args.organizationId, templates, args.dump, args.dryRun)
+}
+
+func mergeTemplates(current []policy.PolicyTemplate, desired []policy.PolicyTemplate) []policy.PolicyTemplate {
+	byName := make(map[string]policy.PolicyTemplate)
+	for _, template := range current {
+		byName[template.Name] = template
+	}
+	for _, template := range desired {
+		byName[template.Name] = template
+	}
+	merged := make([]policy.PolicyTemplate, 0, len(byName))
+	for _, template := range byName {
+		merged = append(merged, template)
+	}
+	return merged
+}
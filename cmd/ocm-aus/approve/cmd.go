@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approve
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/policy"
+)
+
+var args struct {
+	organizationId string
+	clusterName    string
+	sector         string
+	version        string
+	dryRun         bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve a version for clusters that require an approval",
+	Long: "Approve a version for a single cluster with --cluster-name or for all clusters of a sector with --sector.\n" +
+		"Clusters with the require_approval condition are only upgraded to approved versions. " +
+		"The approval records the approving OCM account and the time of the approval.",
+	GroupID:       "AUS commands",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization that owns the clusters. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.StringVarP(
+		&args.clusterName,
+		"cluster-name",
+		"c",
+		"",
+		"Name of the cluster to approve the version for. "+
+			"This name needs to match the cluster name in OCM.",
+	)
+	flags.StringVar(
+		&args.sector,
+		"sector",
+		"",
+		"Approve the version for all clusters of this sector.",
+	)
+	flags.StringVar(
+		&args.version,
+		"version",
+		"",
+		"The version to approve, e.g. 4.16.3.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the approval is only printed to stdout.",
+	)
+	_ = Cmd.MarkFlagRequired("version")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if (args.clusterName == "") == (args.sector == "") {
+		return errors.New("exactly one of --cluster-name or --sector needs to be provided")
+	}
+	if _, err := semver.StrictNewVersion(args.version); err != nil {
+		return fmt.Errorf("invalid version %s: %v", args.version, err)
+	}
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	account, err := ocm.Whoami(cmd.Context(), connection)
+	if err != nil {
+		return err
+	}
+	approval := policy.NewApproval(args.version, args.sector, account.Username(), time.Now())
+	if args.clusterName != "" {
+		return be.ApproveClusterVersion(cmd.Context(), args.organizationId, args.clusterName, approval, args.dryRun)
+	}
+	return be.ApproveSectorVersion(cmd.Context(), args.organizationId, approval, args.dryRun)
+}
@@ -0,0 +1,36 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/check/inheritance"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:           "check",
+	Short:         "Verify AUS configurations",
+	Long:          "Verify AUS configurations",
+	GroupID:       "AUS commands",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	// Register the subcommands:
+	Cmd.AddCommand(inheritance.Cmd)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inheritance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/versiondata"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	organizationId string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "inheritance",
+	Short: "Verify the version data inheritance configuration across organizations",
+	Long: `Verify the version data inheritance configuration across organizations.
+
+Starting with an organization, all organizations referenced by inherit-from and publish-to
+entries are inspected transitively. Organizations that are not found with the current connection
+are looked up in all profiles, so inheritance across OCM environments is covered as well.
+
+The check reports
+* organizations inheriting from an organization that does not publish to them, and vice versa
+* referenced organizations that can't be found or read
+* cycles of inherit-from references
+
+The command exits with a non-zero exit code if any problem is found.`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to start the check with. "+
+			"Defaults to the organization of the logged in user.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	ctx := cmd.Context()
+	connections := ocm.NewConnectionManager()
+	defer connections.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+
+	organizationId := args.organizationId
+	if organizationId == "" {
+		connection, err := connections.Default()
+		if err != nil {
+			return err
+		}
+		organizationId, err = ocm.CurrentOrganizationId(ctx, connection)
+		if err != nil {
+			return err
+		}
+	}
+
+	lookup := backend.NewInheritanceLookup(ctx, backendType, connections)
+	graph := versiondata.WalkInheritance(organizationId, lookup)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	problems := graph.Check()
+
+	description, err := output.TabbedString(func(out io.Writer) error {
+		w := output.NewPrefixWriter(out, "")
+		w1 := output.NewPrefixWriter(out, "  ")
+		w.WriteString("Organizations:\t(%d in total)\n", len(graph.Organizations))
+		w1.WriteString("Organization ID\tName\tOCM environment\tInherit from\tPublish to\n")
+		w1.WriteString("---------------\t----\t---------------\t------------\t----------\n")
+		for _, org := range graph.SortedOrganizations() {
+			w1.WriteString(
+				"%s\t%s\t%s\t%s\t%s\n",
+				org.OrganizationId,
+				org.Name,
+				org.Environment,
+				joinOrNone(org.Config.InheritEntries()),
+				joinOrNone(org.Config.PublishEntries()),
+			)
+		}
+		if len(problems) == 0 {
+			w.WriteString("No problems found\n")
+			return nil
+		}
+		w.WriteString("Problems:\t(%d in total)\n", len(problems))
+		for _, problem := range problems {
+			w1.WriteString("%s: %s\n", problem.Kind, problem.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(description)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in the version data inheritance configuration", len(problems))
+	}
+	return nil
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ", ")
+}
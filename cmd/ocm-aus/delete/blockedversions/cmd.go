@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockedversions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+)
+
+var args struct {
+	organizationId string
+	all            bool
+	dryRun         bool
+	yes            bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "version-blocks [--all | EXPRESSION...]",
+	Short: "Delete blocked versions",
+	Long: "Delete the given blocked version expressions or, with --all, all blocked versions of an organization.\n" +
+		"Expressions are matched the same way as with 'apply version-blocks --unblock-version'.",
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization that owns the blocked versions. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.BoolVar(
+		&args.all,
+		"all",
+		false,
+		"Delete all blocked versions.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the changes are only printed to stdout.",
+	)
+	flags.BoolVarP(
+		&args.yes,
+		"yes",
+		"y",
+		false,
+		"Delete without asking for confirmation.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if args.all == (len(argv) > 0) {
+		return errors.New("either --all or version expressions need to be provided")
+	}
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	expressions := argv
+	if args.all {
+		expressions, err = be.ListBlockedVersionExpressions(cmd.Context(), args.organizationId)
+		if err != nil {
+			return err
+		}
+		if len(expressions) == 0 {
+			fmt.Println("No blocked versions to delete")
+			return nil
+		}
+	}
+
+	if !args.dryRun && !args.yes {
+		confirmed, err := output.Confirm("Delete blocked versions %s?", strings.Join(expressions, ", "))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+	return be.DeleteBlockedVersionExpressions(cmd.Context(), args.organizationId, expressions, args.dryRun)
+}
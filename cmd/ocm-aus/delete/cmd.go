@@ -17,7 +17,10 @@ limitations under the License.
 package delete
 
 import (
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/delete/blockedversions"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/delete/inheritance"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/delete/policy"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/delete/sector"
 	"github.com/spf13/cobra"
 )
 
@@ -33,4 +36,7 @@ var Cmd = &cobra.Command{
 func init() {
 	// Register the subcommands:
 	Cmd.AddCommand(policy.Cmd)
+	Cmd.AddCommand(sector.Cmd)
+	Cmd.AddCommand(blockedversions.Cmd)
+	Cmd.AddCommand(inheritance.Cmd)
 }
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inheritance
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+)
+
+var args struct {
+	organizationId string
+	dryRun         bool
+	yes            bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "inheritance",
+	Short: "Delete the version data inheritance configuration",
+	Long: "Delete all inherit-from and publish-to entries of an organization, including their workload filters.\n" +
+		"Organizations that publish to or inherit from this organization are not changed.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to manage. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the changes are only printed to stdout.",
+	)
+	flags.BoolVarP(
+		&args.yes,
+		"yes",
+		"y",
+		false,
+		"Delete without asking for confirmation.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	if !args.dryRun && !args.yes {
+		confirmed, err := output.Confirm("Delete the version data inheritance configuration?")
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+	return be.DeleteVersionDataInheritanceConfiguration(cmd.Context(), args.organizationId, args.dryRun)
+}
@@ -17,21 +17,41 @@ limitations under the License.
 package policy
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/clusters"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
 )
 
 var args struct {
 	organizationId string
 	clusterName    string
+	sector         string
+	workload       string
+	namePattern    string
+	labels         []string
+	fromFile       string
+	yes            bool
+	forceOwner     bool
 	dryRun         bool
 }
 
 var Cmd = &cobra.Command{
 	Use:   "policy",
 	Short: "Delete a policy",
-	RunE:  run,
+	Long: "Delete the upgrade policy of a single cluster with --cluster-name, of all clusters matching a selector " +
+		"(--sector, --workload, --name, --label) or of the clusters listed in a file (--from-file).\n" +
+		"When deleting multiple policies, the affected clusters are shown and need to be confirmed unless --yes is given.",
+	RunE: run,
 }
 
 func init() {
@@ -53,7 +73,51 @@ func init() {
 		"Name of the cluster that holds the policy to delete. "+
 			"This name needs to match the cluster name in OCM.",
 	)
-	_ = Cmd.MarkFlagRequired("cluster-name")
+	flags.StringVar(
+		&args.sector,
+		"sector",
+		"",
+		"Delete the policies of all clusters in this sector.",
+	)
+	flags.StringVar(
+		&args.workload,
+		"workload",
+		"",
+		"Delete the policies of all clusters running this workload.",
+	)
+	flags.StringVar(
+		&args.namePattern,
+		"name",
+		"",
+		"Delete the policies of all clusters with a name matching this glob pattern, e.g. 'prod-*'.",
+	)
+	flags.StringArrayVar(
+		&args.labels,
+		"label",
+		[]string{},
+		"key=value ... Delete the policies of all clusters whose subscription has this label. Can be specified multiple times.",
+	)
+	flags.StringVarP(
+		&args.fromFile,
+		"from-file",
+		"f",
+		"",
+		"Delete the policies of the clusters listed in this file, one cluster name per line. "+
+			"Empty lines and lines starting with # are ignored. Use - to read from stdin.",
+	)
+	flags.BoolVarP(
+		&args.yes,
+		"yes",
+		"y",
+		false,
+		"Delete without asking for confirmation.",
+	)
+	flags.BoolVar(
+		&args.forceOwner,
+		"force-owner",
+		false,
+		"Delete policies even if they are owned by another team.",
+	)
 	flags.BoolVar(
 		&args.dryRun,
 		"dry-run",
@@ -63,15 +127,121 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	labels, err := clusters.ParseLabelSelectors(args.labels)
+	if err != nil {
+		return err
+	}
+	selector := clusters.Selector{
+		Sector:   args.sector,
+		Workload: args.workload,
+		Name:     args.namePattern,
+		Labels:   labels,
+	}
+	if err := selector.Validate(); err != nil {
+		return err
+	}
+	modes := 0
+	for _, set := range []bool{args.clusterName != "", !selector.Empty(), args.fromFile != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return errors.New("exactly one of --cluster-name, a selector or --from-file needs to be provided")
+	}
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	guard, err := backend.NewOwnerGuard(args.forceOwner)
 	if err != nil {
 		return err
 	}
 
 	// delete policy
-	return be.DeletePolicy(args.organizationId, args.clusterName, args.dryRun)
+	if args.clusterName != "" {
+		return be.DeletePolicy(cmd.Context(), args.organizationId, args.clusterName, guard, args.dryRun)
+	}
+
+	var clusterNames []string
+	if args.fromFile != "" {
+		clusterNames, err = readClusterNames(args.fromFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		clusterNames, err = selectClusterNames(cmd, be, selector)
+		if err != nil {
+			return err
+		}
+	}
+	if len(clusterNames) == 0 {
+		fmt.Println("No cluster upgrade policies to delete")
+		return nil
+	}
+
+	fmt.Printf("Cluster upgrade policies to delete (%d in total):\n", len(clusterNames))
+	for _, clusterName := range clusterNames {
+		fmt.Printf("  %s\n", clusterName)
+	}
+	if !args.dryRun && !args.yes {
+		confirmed, err := output.Confirm("Delete %d cluster upgrade policies?", len(clusterNames))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+	_, err = be.DeletePolicies(cmd.Context(), args.organizationId, clusterNames, guard, args.dryRun)
+	return err
+}
+
+func selectClusterNames(cmd *cobra.Command, be backend.PolicyBackend, selector clusters.Selector) ([]string, error) {
+	policies, err := be.ListPolicies(cmd.Context(), args.organizationId, false)
+	if err != nil {
+		return nil, err
+	}
+	clusterInfos := make([]*clusters.ClusterInfo, 0, len(policies))
+	for _, clusterInfo := range policies {
+		clusterInfos = append(clusterInfos, clusterInfo)
+	}
+	clusterNames := []string{}
+	for _, clusterInfo := range clusters.SelectClusters(clusterInfos, selector) {
+		clusterNames = append(clusterNames, clusterInfo.DisplayName())
+	}
+	return clusterNames, nil
+}
+
+func readClusterNames(path string) ([]string, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+	clusterNames := []string{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		clusterNames = append(clusterNames, line)
+	}
+	return clusterNames, scanner.Err()
 }
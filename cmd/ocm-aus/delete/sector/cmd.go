@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sector
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+)
+
+var args struct {
+	organizationId string
+	force          bool
+	dryRun         bool
+	yes            bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "sector NAME",
+	Aliases: []string{"sectors"},
+	Short:   "Delete a sector",
+	Long: "Delete the dependencies and the max parallel upgrades setting of a sector.\n" +
+		"A sector can't be deleted while other sectors depend on it. Sectors that are still used by\n" +
+		"cluster policies are only deleted with --force.",
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization that owns the sector. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.BoolVar(
+		&args.force,
+		"force",
+		false,
+		"Delete the sector even if cluster policies still use it.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the changes are only printed to stdout.",
+	)
+	flags.BoolVarP(
+		&args.yes,
+		"yes",
+		"y",
+		false,
+		"Delete without asking for confirmation.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	sectorName := argv[0]
+	if !args.dryRun && !args.yes {
+		// only ask for a deletion that can succeed
+		if err := be.CheckDeleteSector(cmd.Context(), args.organizationId, sectorName, args.force); err != nil {
+			return err
+		}
+		confirmed, err := output.Confirm("Delete sector %s?", sectorName)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("aborted")
+			return nil
+		}
+	}
+	return be.DeleteSector(cmd.Context(), args.organizationId, sectorName, args.force, args.dryRun)
+}
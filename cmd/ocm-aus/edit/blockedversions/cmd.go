@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blockedversions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/editor"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/versions"
+)
+
+var args struct {
+	organizationId string
+	format         string
+	dryRun         bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "version-blocks",
+	Short: "Edit the blocked versions of an organization",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to manage. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		editor.FormatYAML,
+		fmt.Sprintf("The format to edit the blocked versions in (%s, %s).", editor.FormatYAML, editor.FormatJSON),
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the changes are only printed to stdout.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	ed, err := editor.NewEditor(args.format)
+	if err != nil {
+		return err
+	}
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	current, err := be.ListBlockedVersionExpressions(cmd.Context(), args.organizationId)
+	if err != nil {
+		return err
+	}
+	var edited []string
+	original, result, err := ed.Edit("version-blocks", nil, current, &edited, func() error {
+		_, err := versions.ParsedBlockedVersionExpressions(edited)
+		return err
+	})
+	if errors.Is(err, editor.ErrCancelled) {
+		fmt.Println("Edit cancelled, no changes made")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	diff, err := editor.Diff(original, result)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+	blockExpressions := versions.ConsolidateVersionBlocks(edited, nil, nil)
+	return be.ApplyBlockedVersionExpressions(cmd.Context(), args.organizationId, blockExpressions, false, args.dryRun)
+}
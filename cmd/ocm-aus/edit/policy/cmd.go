@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/editor"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/schedule"
+)
+
+var args struct {
+	organizationId string
+	format         string
+	forceOwner     bool
+	dryRun         bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "policy CLUSTER_NAME",
+	Short: "Edit the upgrade policy of a cluster",
+	Args:  cobra.ExactArgs(1),
+	RunE:  run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization that owns the cluster. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		editor.FormatYAML,
+		fmt.Sprintf("The format to edit the policy in (%s, %s).", editor.FormatYAML, editor.FormatJSON),
+	)
+	flags.BoolVar(
+		&args.forceOwner,
+		"force-owner",
+		false,
+		"Change policies even if they are owned by another team.",
+	)
+	flags.BoolVar(
+		&args.dryRun,
+		"dry-run",
+		false,
+		"If dry-run is specified, the changes are only printed to stdout.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	clusterName := argv[0]
+	ed, err := editor.NewEditor(args.format)
+	if err != nil {
+		return err
+	}
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	guard, err := backend.NewOwnerGuard(args.forceOwner)
+	if err != nil {
+		return err
+	}
+	clusterInfos, err := be.ListPolicies(cmd.Context(), args.organizationId, true)
+	if err != nil {
+		return err
+	}
+	clusterInfo, ok := clusterInfos[clusterName]
+	if !ok {
+		return fmt.Errorf("cluster %s not found", clusterName)
+	}
+	// check the ownership before the policy is edited, not only once it is applied
+	if err := guard.Check(clusterName, clusterInfo.Policy.Owner); err != nil {
+		return err
+	}
+	defaults, err := be.GetPolicyDefaults(cmd.Context(), args.organizationId)
+	if err != nil {
+		return err
+	}
+
+	// only the values set on the cluster are edited, inherited values are shown as comments
+	current := clusterInfo.Policy.WithoutInherited()
+	comments := []string{}
+	if len(clusterInfo.Policy.Inherited) > 0 {
+		comments = append(comments, "", "Values inherited from the policy defaults of the organization:")
+		inherited := defaults.Merge(policy.ClusterUpgradePolicy{})
+		for _, field := range clusterInfo.Policy.Inherited {
+			comments = append(comments, fmt.Sprintf("  %s: %s", field, inheritedValue(inherited, field)))
+		}
+	}
+	var edited policy.ClusterUpgradePolicy
+	original, result, err := ed.Edit(clusterName, comments, current, &edited, func() error {
+		if edited.ClusterName != clusterName {
+			return errors.New("the cluster name can't be changed")
+		}
+		if edited.Schedule != "" {
+			if _, err := schedule.TranslateSchedule(edited.Schedule); err != nil {
+				return err
+			}
+		}
+		if err := edited.ValidateExplicit(); err != nil {
+			return err
+		}
+		return defaults.Merge(edited).Validate()
+	})
+	if errors.Is(err, editor.ErrCancelled) {
+		fmt.Println("Edit cancelled, no changes made")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	diff, err := editor.Diff(original, result)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+	if edited.Schedule != "" {
+		edited.Schedule, _ = schedule.TranslateSchedule(edited.Schedule)
+	}
+	_, err = be.ApplyPolicies(cmd.Context(), args.organizationId, []policy.ClusterUpgradePolicy{edited}, policy.ApplyOptions{
+		DryRun:      args.dryRun,
+		Concurrency: 1,
+		OwnerGuard:  guard,
+	})
+	return err
+}
+
+func inheritedValue(p policy.ClusterUpgradePolicy, field string) string {
+	switch field {
+	case policy.ScheduleField:
+		return p.Schedule
+	case policy.SoakDaysField:
+		return fmt.Sprint(p.SoakDays())
+	case policy.SectorField:
+		return p.Conditions.Sector
+	case policy.MutexesField:
+		return fmt.Sprint(p.Conditions.Mutexes)
+	}
+	return ""
+}
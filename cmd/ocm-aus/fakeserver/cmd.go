@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/ocm/fake"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	fixture string
+	address string
+	save    string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "fake-server",
+	Short: "Run a local fake OCM API server",
+	Long: "Run an in-memory fake of the OCM API parts used by AUS. The server can be seeded from a fixture file " +
+		"and is meant for testing and for rehearsing changes against a copy of real organization data.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.fixture,
+		"fixture",
+		"f",
+		"",
+		"JSON fixture file to seed the server with.",
+	)
+	flags.StringVar(
+		&args.address,
+		"listen",
+		"127.0.0.1:8000",
+		"Address to listen on.",
+	)
+	flags.StringVar(
+		&args.save,
+		"save",
+		"",
+		"Write the state of the server as a fixture to this file when the server stops.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	fixture := &fake.Fixture{}
+	if args.fixture != "" {
+		var err error
+		fixture, err = fake.LoadFixture(args.fixture)
+		if err != nil {
+			return err
+		}
+	}
+	server, err := fake.NewServer(fixture)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", args.address)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("http://%s", listener.Addr().String())
+	fmt.Printf("Fake OCM API server listening on %s\n", url)
+	fmt.Printf("Log in with: ocm login --url %s --token %s\n", url, ocm.UnsignedAccessToken("fake-user"))
+
+	httpServer := &http.Server{Handler: server}
+	go func() {
+		<-cmd.Context().Done()
+		_ = httpServer.Shutdown(context.Background())
+	}()
+	err = httpServer.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if args.save != "" {
+		err = server.Fixture().Save(args.save)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved server state to %s\n", args.save)
+	}
+	return nil
+}
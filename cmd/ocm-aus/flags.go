@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/spf13/pflag"
+)
+
+// addConnectionFlags adds the flags that select the OCM environment and control the OCM API
+// requests to the given set of command line flags.
+func addConnectionFlags(flags *pflag.FlagSet, options *ocm.ConnectionOptions) {
+	flags.StringVar(
+		&options.Profile,
+		"profile",
+		"",
+		"Name of the profile to connect with. Profiles define the URL and credentials of an OCM environment "+
+			"and are read from the file given by OCM_AUS_PROFILES or from ocm-aus/profiles.json in the user config directory.",
+	)
+	flags.StringVar(
+		&options.ConfigFile,
+		"ocm-config",
+		"",
+		"Path of the OCM configuration file to use instead of the one written by 'ocm login'. "+
+			"Ignored if a profile is selected or if credentials are set with the OCM_TOKEN or OCM_CLIENT_ID and OCM_CLIENT_SECRET environment variables.",
+	)
+	flags.IntVar(
+		&options.Retry.MaxRetries,
+		"max-retries",
+		options.Retry.MaxRetries,
+		"Maximum number of retries for failed OCM API requests.",
+	)
+	flags.DurationVar(
+		&options.Retry.Timeout,
+		"timeout",
+		options.Retry.Timeout,
+		"Timeout for a single OCM API request. 0 disables the timeout.",
+	)
+	flags.StringVar(
+		&options.RecordDir,
+		"record",
+		"",
+		"Record all OCM API requests and responses into the given directory. Tokens and secrets are redacted.",
+	)
+	flags.StringVar(
+		&options.ReplayDir,
+		"replay",
+		"",
+		"Answer OCM API requests from a recording made with --record instead of contacting the OCM API.",
+	)
+}
@@ -18,9 +18,12 @@ package get
 
 import (
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/blockedversions"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/defaults"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/gates"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/inheritance"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/policy"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/sector"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/get/templates"
 	"github.com/spf13/cobra"
 )
 
@@ -39,4 +42,7 @@ func init() {
 	Cmd.AddCommand(sector.Cmd)
 	Cmd.AddCommand(blockedversions.Cmd)
 	Cmd.AddCommand(gates.Cmd)
+	Cmd.AddCommand(inheritance.Cmd)
+	Cmd.AddCommand(templates.Cmd)
+	Cmd.AddCommand(defaults.Cmd)
 }
@@ -52,35 +52,38 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	backendType, err := cmd.Flags().GetString("backend")
+	connection, err := ocm.NewOCMConnection()
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-
-	// assemble data
-	connection, err := ocm.NewOCMConnection()
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
 
+	// assemble data
+	ctx := cmd.Context()
+
 	organizationId := args.organizationId
 	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
+		organizationId, err = ocm.CurrentOrganizationId(ctx, connection)
 		if err != nil {
 			return err
 		}
 	}
-	clusterInfos, err := clusters.ClusterInfosForOrganization(organizationId, "", true, connection)
+	clusterInfos, err := clusters.ClusterInfosForOrganization(ctx, organizationId, "", true, connection)
 	if err != nil {
 		return err
 	}
 	clusters.SortClusters(clusterInfos)
 
-	blockedVersions, err := be.ListBlockedVersionExpressions(args.organizationId)
+	blockedVersions, err := be.ListBlockedVersionExpressions(ctx, args.organizationId)
 	if err != nil {
 		return err
 	}
@@ -89,12 +92,12 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
-	organization, err := ocm.GetOrganization(args.organizationId, connection)
+	organization, err := ocm.GetOrganization(ctx, args.organizationId, connection)
 	if err != nil {
 		return err
 	}
 
-	versionGates, err := ocm.GetVersionGates(connection)
+	versionGates, err := ocm.GetVersionGates(ctx, connection)
 	if err != nil {
 		return err
 	}
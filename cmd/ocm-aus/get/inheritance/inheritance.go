@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inheritance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/versiondata"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	organizationId string
+	graph          bool
+	format         string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "inheritance",
+	Short: "Lists the version data inheritance configuration for an organization",
+	Long: "Lists the version data inheritance configuration for an organization.\n" +
+		"\n" +
+		"With --graph, the inherit-from and publish-to entries are followed transitively and the resulting\n" +
+		"graph of organizations is printed in DOT or Mermaid format. Edges point in the direction version\n" +
+		"data flows. Edges that are declared by only one side are marked. Organizations that are not found\n" +
+		"with the current connection are looked up with all profiles.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization to inspect",
+	)
+	flags.BoolVar(
+		&args.graph,
+		"graph",
+		false,
+		"Print the graph of all organizations connected by inheritance.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		"dot",
+		"Output format of the graph. Supported: dot, mermaid",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	ctx := cmd.Context()
+	if args.format != "dot" && args.format != "mermaid" {
+		return fmt.Errorf("unsupported graph format '%s', use dot or mermaid", args.format)
+	}
+
+	connections := ocm.NewConnectionManager()
+	defer connections.Close()
+	connection, err := connections.Default()
+	if err != nil {
+		return err
+	}
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+
+	if !args.graph {
+		be, err := backend.NewPolicyBackend(backendType, connection)
+		if err != nil {
+			return err
+		}
+		config, err := be.GetVersionDataInheritanceConfiguration(ctx, args.organizationId)
+		if err != nil {
+			return err
+		}
+		body, _ := json.Marshal(config)
+		return output.PrettyList(os.Stdout, body)
+	}
+
+	organizationId := args.organizationId
+	if organizationId == "" {
+		organizationId, err = ocm.CurrentOrganizationId(ctx, connection)
+		if err != nil {
+			return err
+		}
+	}
+	graph := versiondata.WalkInheritance(organizationId, backend.NewInheritanceLookup(ctx, backendType, connections))
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if _, ok := graph.Unreachable[organizationId]; ok {
+		return graph.Unreachable[organizationId]
+	}
+	for orgId, err := range graph.Unreachable {
+		fmt.Fprintf(os.Stderr, "Warning: organization %s is unreachable: %v\n", orgId, err)
+	}
+
+	switch args.format {
+	case "mermaid":
+		fmt.Print(graph.Mermaid())
+	default:
+		fmt.Print(graph.Dot())
+	}
+	return nil
+}
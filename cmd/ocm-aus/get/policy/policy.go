@@ -23,12 +23,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/app-sre/aus-cli/pkg/policy"
 )
 
 var args struct {
 	organizationId string
+	owner          string
 }
 
 var Cmd = &cobra.Command{
@@ -47,19 +49,31 @@ func init() {
 		"The ID of the OCM organization that owns the cluster. "+
 			"Defaults to the organization of the logged in user.",
 	)
+	flags.StringVar(
+		&args.owner,
+		"owner",
+		"",
+		"Only list the policies owned by this team.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	fe, err := backend.NewPolicyBackend(backendType)
+	fe, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
 
-	clusters, err := fe.ListPolicies(args.organizationId, false)
+	clusters, err := fe.ListPolicies(cmd.Context(), args.organizationId, false)
 	if err != nil {
 		return err
 	}
@@ -68,6 +82,9 @@ func run(cmd *cobra.Command, argv []string) error {
 	policiesSlice := []policy.ClusterUpgradePolicy{}
 
 	for _, c := range clusters {
+		if args.owner != "" && !c.OwnedBy(args.owner) {
+			continue
+		}
 		policiesSlice = append(policiesSlice, *c.Policy)
 	}
 	body, err := json.Marshal(policiesSlice)
@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
@@ -48,15 +49,21 @@ func init() {
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
-	sectorConfiguration, err := be.ListSectorConfiguration(args.organizationId)
+	sectorConfiguration, err := be.ListSectorConfiguration(cmd.Context(), args.organizationId)
 	if err != nil {
 		return err
 	}
@@ -17,16 +17,25 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/apply"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/approve"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/check"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/delete"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/edit"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/fakeserver"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/get"
+	"github.com/app-sre/aus-cli/cmd/ocm-aus/render"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/status"
 	"github.com/app-sre/aus-cli/cmd/ocm-aus/version"
 	"github.com/app-sre/aus-cli/pkg/arguments"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 var root = &cobra.Command{
@@ -35,8 +44,14 @@ var root = &cobra.Command{
 	Long:          "This plug-in extends the ocm-cli to provide additional commands for working with Advanced Upgrade Service",
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, argv []string) {
+		ocm.SetConnectionOptions(connectionOptions)
+	},
 }
 
+// connectionOptions are set by the global flags and used for all OCM connections.
+var connectionOptions = ocm.DefaultConnectionOptions()
+
 func init() {
 	// Send logs to the standard error stream by default:
 	err := flag.Set("logtostderr", "true")
@@ -48,6 +63,7 @@ func init() {
 	// Add the command line flags:
 	fs := root.PersistentFlags()
 	arguments.AddDebugFlag(fs)
+	addConnectionFlags(fs, &connectionOptions)
 
 	root.PersistentFlags().String("backend", "ocmlabels", "Backend to store policies in. Supported: ocmlabels")
 
@@ -57,7 +73,12 @@ func init() {
 	root.AddCommand(apply.Cmd)
 	root.AddCommand(status.Cmd)
 	root.AddCommand(delete.Cmd)
+	root.AddCommand(edit.Cmd)
+	root.AddCommand(check.Cmd)
+	root.AddCommand(render.Cmd)
+	root.AddCommand(approve.Cmd)
 	root.AddCommand(version.Cmd)
+	root.AddCommand(fakeserver.Cmd)
 }
 
 func main() {
@@ -70,9 +91,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Cancel in-flight requests when the user interrupts the command:
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	// Execute the root command and exit immediately if there was no error:
 	root.SetArgs(os.Args[1:])
-	err = root.Execute()
+	err = root.ExecuteContext(ctx)
+	stop()
 	if err == nil {
 		os.Exit(0)
 	}
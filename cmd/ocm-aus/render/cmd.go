@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/app-sre/aus-cli/pkg/backend"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var args struct {
+	organizationId string
+	templateFile   string
+	templates      []string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render policy templates into cluster upgrade policies",
+	Long: "Render policy templates into cluster upgrade policies.\n" +
+		"\n" +
+		"The templates are read from the file given by --template-file or from the organization. " +
+		"Every template is rendered for each cluster matching its selector and the resulting policies " +
+		"are printed in the format accepted by 'ocm aus apply policies -'.\n" +
+		"\n" +
+		"The businessHours template function approximates the business hours of a region in UTC: the window is cut " +
+		"where it crosses UTC midnight and daylight saving time is not considered.\n",
+	GroupID:       "AUS commands",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.SortFlags = false
+	flags.StringVarP(
+		&args.organizationId,
+		"org-id",
+		"o",
+		"",
+		"The ID of the OCM organization that owns the clusters. "+
+			"Defaults to the organization of the logged in user.",
+	)
+	flags.StringVarP(
+		&args.templateFile,
+		"template-file",
+		"f",
+		"",
+		"Read the policy templates from this file instead of the organization.",
+	)
+	flags.StringArrayVarP(
+		&args.templates,
+		"template",
+		"t",
+		[]string{},
+		"Name of the template to render. Can be specified multiple times. Defaults to all templates.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	backendType, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return err
+	}
+	be, err := backend.NewPolicyBackend(backendType, connection)
+	if err != nil {
+		return err
+	}
+
+	templates, err := backend.LoadPolicyTemplates(cmd.Context(), be, args.organizationId, args.templateFile, args.templates)
+	if err != nil {
+		return err
+	}
+	policies, err := be.RenderPolicyTemplates(cmd.Context(), args.organizationId, templates)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+	return output.PrettyList(os.Stdout, body)
+}
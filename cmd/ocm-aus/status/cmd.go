@@ -19,11 +19,14 @@ package status
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/app-sre/aus-cli/pkg/backend"
 	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/utils"
 	"github.com/app-sre/aus-cli/pkg/versions"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +34,7 @@ import (
 var args struct {
 	organizationId  string
 	showAllClusters bool
+	owner           string
 }
 
 var Cmd = &cobra.Command{
@@ -57,28 +61,56 @@ func init() {
 		false,
 		"Show also clusters without defined upgrade policy.",
 	)
+	flags.StringVar(
+		&args.owner,
+		"owner",
+		"",
+		"Only show the clusters whose policy is owned by this team.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
 	backendType, err := cmd.Flags().GetString("backend")
 	if err != nil {
 		return err
 	}
-	be, err := backend.NewPolicyBackend(backendType)
+	be, err := backend.NewPolicyBackend(backendType, connection)
 	if err != nil {
 		return err
 	}
 
 	// assemble data
-	connection, err := ocm.NewOCMConnection()
+	organization, clusters, blockedVersions, sectors, inheritance, err := be.Status(cmd.Context(), args.organizationId, args.showAllClusters)
 	if err != nil {
 		return err
 	}
-	organization, clusters, blockedVersions, sectors, inheritance, err := be.Status(args.organizationId, args.showAllClusters)
+	// the workloads of all clusters are validated, also the ones hidden by --owner
+	workloads := []string{}
+	for _, cluster := range clusters {
+		if cluster.Policy != nil {
+			workloads = append(workloads, cluster.Policy.Workloads...)
+		}
+	}
+	if args.owner != "" {
+		owned := clusters[:0]
+		for _, cluster := range clusters {
+			if cluster.OwnedBy(args.owner) {
+				owned = append(owned, cluster)
+			}
+		}
+		clusters = owned
+	}
+	blockedVersionExpressions, err := versions.ParsedBlockedVersionExpressions(blockedVersions)
 	if err != nil {
 		return err
 	}
-	blockedVersionExpressions, err := versions.ParsedBlockedVersionExpressions(blockedVersions)
+	defaults, err := be.GetPolicyDefaults(cmd.Context(), args.organizationId)
 	if err != nil {
 		return err
 	}
@@ -92,10 +124,17 @@ func run(cmd *cobra.Command, argv []string) error {
 		w.WriteString("OCM environment:\t%s\n", connection.URL())
 		output.PrintListMultiline(w, "Blocked Versions", blockedVersions)
 		if len(inheritance.InheritingFromOrgs) > 0 {
-			w.WriteString("Inherit version data:\t%s\n", strings.Join(inheritance.InheritingFromOrgs, ", "))
+			w.WriteString("Inherit version data:\t%s\n", strings.Join(inheritance.InheritEntries(), ", "))
 		}
 		if len(inheritance.PublishingToOrgs) > 0 {
-			w.WriteString("Publish version data:\t%s\n", strings.Join(inheritance.PublishingToOrgs, ", "))
+			w.WriteString("Publish version data:\t%s\n", strings.Join(inheritance.PublishEntries(), ", "))
+		}
+		if err := inheritance.ValidateWorkloads(workloads); err != nil {
+			w.WriteString("Inheritance warning:\t%v\n", err)
+		}
+
+		if !defaults.Empty() {
+			w.WriteString("Policy defaults:\t%s\n", describeDefaults(defaults))
 		}
 
 		w.WriteString("Sector Configuration:\t(%d in total)\n", len(sectors))
@@ -109,33 +148,39 @@ func run(cmd *cobra.Command, argv []string) error {
 
 		w.WriteString("Clusters:\t(%d in total)\n", len(clusters))
 		if len(clusters) > 0 {
-			w1.WriteString("Cluster Name\tProduct\tVersion\tChannel\tSchedule\tSector\tMutexes\tSoak Days\tWorkloads\tBlocked Versions\tAvailable Upgrades\n")
-			w1.WriteString("------------\t-------\t-------\t-------\t--------\t------\t-------\t---------\t---------\t----------------\t------------------\n")
+			w1.WriteString("Cluster Name\tProduct\tVersion\tChannel\tSchedule\tSector\tMutexes\tSoak Days\tWorkloads\tOwner\tContact\tTicket\tBlocked Versions\tMax Version\tZ-Stream Only\tMin Upgrade Interval\tAvailable Upgrades\n")
+			w1.WriteString("------------\t-------\t-------\t-------\t--------\t------\t-------\t---------\t---------\t-----\t-------\t------\t----------------\t-----------\t-------------\t--------------------\t------------------\n")
 			for _, cluster := range clusters {
 				mutexes := "<none>"
 				sector := "<none>"
 				if cluster.Policy.Validate() == nil {
 					if len(cluster.Policy.Conditions.Mutexes) > 0 {
-						mutexes = strings.Join(cluster.Policy.Conditions.Mutexes, ", ")
+						mutexes = inherited(cluster.Policy, policy.MutexesField, strings.Join(cluster.Policy.Conditions.Mutexes, ", "))
 					}
 					if cluster.Policy.Conditions.Sector != "" {
-						sector = cluster.Policy.Conditions.Sector
+						sector = inherited(cluster.Policy, policy.SectorField, cluster.Policy.Conditions.Sector)
 					}
-					w1.WriteString("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+					w1.WriteString("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 						cluster.Cluster.Name(),
 						cluster.Cluster.Product().ID(),
 						cluster.Cluster.Version().RawID(),
 						cluster.Cluster.Version().ChannelGroup(),
-						cluster.Policy.Schedule,
+						inherited(cluster.Policy, policy.ScheduleField, cluster.Policy.Schedule),
 						sector,
 						mutexes,
-						cluster.Policy.Conditions.SoakDays,
+						inherited(cluster.Policy, policy.SoakDaysField, strconv.Itoa(cluster.Policy.SoakDays())),
 						strings.Join(cluster.Policy.Workloads, ", "),
+						cluster.Policy.Owner,
+						cluster.Policy.Contact,
+						cluster.Policy.Ticket,
 						strings.Join(cluster.Policy.Conditions.BlockedVersions, ", "),
+						cluster.Policy.Conditions.MaxVersion,
+						describeZStreamOnly(cluster.Policy.Conditions.ZStreamOnly),
+						describeInterval(cluster.Policy.Conditions.MinUpgradeIntervalDays),
 						strings.Join(cluster.AvailableUpgrades(false, blockedVersionExpressions), ", "),
 					)
 				} else {
-					w1.WriteString("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					w1.WriteString("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 						cluster.Cluster.Name(),
 						cluster.Cluster.Product().ID(),
 						cluster.Cluster.Version().RawID(),
@@ -145,18 +190,110 @@ func run(cmd *cobra.Command, argv []string) error {
 						mutexes,
 						"<none>",
 						"<none>",
+						cluster.Policy.Owner,
+						cluster.Policy.Contact,
+						cluster.Policy.Ticket,
 						strings.Join(cluster.Policy.Conditions.BlockedVersions, ", "),
+						cluster.Policy.Conditions.MaxVersion,
+						describeZStreamOnly(cluster.Policy.Conditions.ZStreamOnly),
+						describeInterval(cluster.Policy.Conditions.MinUpgradeIntervalDays),
 						strings.Join(cluster.AvailableUpgrades(false, blockedVersionExpressions), ", "),
 					)
 				}
 			}
 		}
 
+		// canaries and the clusters of their workloads that wait for them
+		canaries := [][]string{}
+		for _, canary := range clusters {
+			if canary.Policy.Validate() != nil || !canary.Policy.Conditions.Canary {
+				continue
+			}
+			for _, workload := range canary.Policy.Workloads {
+				waiting := []string{}
+				for _, cluster := range clusters {
+					if cluster == canary || !utils.StringInArray(cluster.Policy.Workloads, workload) {
+						continue
+					}
+					if versions := cluster.HeldBackUpgrades(); len(versions) > 0 {
+						waiting = append(waiting, fmt.Sprintf("%s (%s)", cluster.DisplayName(), strings.Join(versions, ", ")))
+					}
+				}
+				canaries = append(canaries, []string{workload, canary.DisplayName(), canary.Cluster.Version().RawID(), strings.Join(waiting, ", ")})
+			}
+		}
+		if len(canaries) > 0 {
+			w.WriteString("Canaries:\t(%d in total)\n", len(canaries))
+			w1.WriteString("Workload\tCanary\tVersion\tWaiting Clusters\n")
+			w1.WriteString("--------\t------\t-------\t----------------\n")
+			for _, row := range canaries {
+				w1.WriteString("%s\t%s\t%s\t%s\n", row[0], row[1], row[2], row[3])
+			}
+		}
+
+		// clusters that require an approval for some of their available upgrades
+		pendingApprovals := [][]string{}
+		for _, cluster := range clusters {
+			if versions := cluster.PendingApprovals(blockedVersionExpressions); len(versions) > 0 {
+				pendingApprovals = append(pendingApprovals, []string{cluster.DisplayName(), cluster.Policy.Conditions.Sector, strings.Join(versions, ", ")})
+			}
+		}
+		if len(pendingApprovals) > 0 {
+			w.WriteString("Pending approvals:\t(%d in total)\n", len(pendingApprovals))
+			w1.WriteString("Cluster Name\tSector\tVersions\n")
+			w1.WriteString("------------\t------\t--------\n")
+			for _, row := range pendingApprovals {
+				w1.WriteString("%s\t%s\t%s\n", row[0], row[1], row[2])
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
 		return err
 	}
 	fmt.Print(description)
+	if !defaults.Empty() {
+		fmt.Println("Values marked with * are inherited from the policy defaults of the organization.")
+	}
 	return nil
 }
+
+// inherited marks values that are inherited from the organization defaults.
+func inherited(p *policy.ClusterUpgradePolicy, field string, value string) string {
+	if p.IsInherited(field) {
+		return value + "*"
+	}
+	return value
+}
+
+func describeDefaults(defaults policy.PolicyDefaults) string {
+	values := []string{}
+	if defaults.Schedule != "" {
+		values = append(values, fmt.Sprintf("schedule=%s", defaults.Schedule))
+	}
+	if defaults.SoakDays != nil {
+		values = append(values, fmt.Sprintf("soak_days=%d", *defaults.SoakDays))
+	}
+	if defaults.Sector != "" {
+		values = append(values, fmt.Sprintf("sector=%s", defaults.Sector))
+	}
+	if len(defaults.Mutexes) > 0 {
+		values = append(values, fmt.Sprintf("mutexes=%s", strings.Join(defaults.Mutexes, ",")))
+	}
+	return strings.Join(values, ", ")
+}
+
+func describeZStreamOnly(zStreamOnly bool) string {
+	if zStreamOnly {
+		return "yes"
+	}
+	return ""
+}
+
+func describeInterval(days int) string {
+	if days == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package backend
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/app-sre/aus-cli/pkg/backend/ocmlabels"
@@ -24,35 +25,63 @@ import (
 	"github.com/app-sre/aus-cli/pkg/policy"
 	"github.com/app-sre/aus-cli/pkg/sectors"
 	"github.com/app-sre/aus-cli/pkg/versiondata"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
 type PolicyBackend interface {
-	ListPolicies(organizationId string, showClustersWithoutPolicy bool) (map[string]*clusters.ClusterInfo, error)
+	ListPolicies(ctx context.Context, organizationId string, showClustersWithoutPolicy bool) (map[string]*clusters.ClusterInfo, error)
 
-	ApplyPolicies(organizationId string, policies []policy.ClusterUpgradePolicy, dumpPolicy bool, dryRun bool) error
+	ApplyPolicies(ctx context.Context, organizationId string, policies []policy.ClusterUpgradePolicy, options policy.ApplyOptions) ([]policy.ApplyResult, error)
 
-	DeletePolicy(organizationId string, clusterName string, dryRun bool) error
+	DeletePolicy(ctx context.Context, organizationId string, clusterName string, guard policy.OwnerGuard, dryRun bool) error
 
-	ListBlockedVersionExpressions(organizationId string) ([]string, error)
+	DeletePolicies(ctx context.Context, organizationId string, clusterNames []string, guard policy.OwnerGuard, dryRun bool) ([]policy.ApplyResult, error)
 
-	ApplyBlockedVersionExpressions(organizationId string, blockExpressions []string, dumpVersionBlocks bool, dryRun bool) error
+	GetPolicyDefaults(ctx context.Context, organizationId string) (policy.PolicyDefaults, error)
 
-	ListSectorConfiguration(organizationId string) ([]sectors.Sector, error)
+	ApplyPolicyDefaults(ctx context.Context, organizationId string, defaults policy.PolicyDefaults, dumpDefaults bool, dryRun bool) error
 
-	ApplySectorConfiguration(organizationId string, sectors []sectors.Sector, dumpSectors bool, dryRun bool) error
+	ListPolicyTemplates(ctx context.Context, organizationId string) ([]policy.PolicyTemplate, error)
 
-	GetVersionDataInheritanceConfiguration(organizationId string) (versiondata.VersionDataInheritanceConfig, error)
+	ApplyPolicyTemplates(ctx context.Context, organizationId string, templates []policy.PolicyTemplate, dumpTemplates bool, dryRun bool) error
 
-	ApplyVersionDataInheritanceConfiguration(organizationId string, inheritance versiondata.VersionDataInheritanceConfig, dumpConfig bool, dryRun bool) error
+	RenderPolicyTemplates(ctx context.Context, organizationId string, templates []policy.PolicyTemplate) ([]policy.ClusterUpgradePolicy, error)
 
-	Status(organizationId string, showClustersWithoutPolicy bool) (organization *amv1.Organization, clusterInfos []*clusters.ClusterInfo, blockedVersions []string, sectors []sectors.Sector, inheritance versiondata.VersionDataInheritanceConfig, err error)
+	ListBlockedVersionExpressions(ctx context.Context, organizationId string) ([]string, error)
+
+	ApplyBlockedVersionExpressions(ctx context.Context, organizationId string, blockExpressions []string, dumpVersionBlocks bool, dryRun bool) error
+
+	DeleteBlockedVersionExpressions(ctx context.Context, organizationId string, blockExpressions []string, dryRun bool) error
+
+	ListSectorConfiguration(ctx context.Context, organizationId string) ([]sectors.Sector, error)
+
+	ApplySectorConfiguration(ctx context.Context, organizationId string, sectors []sectors.Sector, dumpSectors bool, dryRun bool) error
+
+	ApproveClusterVersion(ctx context.Context, organizationId string, clusterName string, approval policy.Approval, dryRun bool) error
+
+	ApproveSectorVersion(ctx context.Context, organizationId string, approval policy.Approval, dryRun bool) error
+
+	// CheckDeleteSector reports why DeleteSector would refuse to delete the sector, without deleting it.
+	CheckDeleteSector(ctx context.Context, organizationId string, sectorName string, force bool) error
+
+	DeleteSector(ctx context.Context, organizationId string, sectorName string, force bool, dryRun bool) error
+
+	GetVersionDataInheritanceConfiguration(ctx context.Context, organizationId string) (versiondata.VersionDataInheritanceConfig, error)
+
+	ApplyVersionDataInheritanceConfiguration(ctx context.Context, organizationId string, inheritance versiondata.VersionDataInheritanceConfig, dumpConfig bool, dryRun bool) error
+
+	DeleteVersionDataInheritanceConfiguration(ctx context.Context, organizationId string, dryRun bool) error
+
+	Status(ctx context.Context, organizationId string, showClustersWithoutPolicy bool) (organization *amv1.Organization, clusterInfos []*clusters.ClusterInfo, blockedVersions []string, sectors []sectors.Sector, inheritance versiondata.VersionDataInheritanceConfig, err error)
 }
 
-func NewPolicyBackend(backendType string) (PolicyBackend, error) {
+// NewPolicyBackend creates a backend of the given type. All requests of the backend are done
+// through the given connection.
+func NewPolicyBackend(backendType string, connection *sdk.Connection) (PolicyBackend, error) {
 	switch backendType {
 	case "ocmlabels", "":
-		return ocmlabels.NewOCMLabelsPolicyBackend(), nil
+		return ocmlabels.NewOCMLabelsPolicyBackend(connection), nil
 	default:
 		return nil, fmt.Errorf("unknown backend type: %s", backendType)
 	}
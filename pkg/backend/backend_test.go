@@ -0,0 +1,828 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/app-sre/aus-cli/pkg/backend/ocmlabels"
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/ocm/fake"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/utils"
+	"github.com/app-sre/aus-cli/pkg/versiondata"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+const testOrganizationId = "org1"
+
+func testFixture() *fake.Fixture {
+	fixture := &fake.Fixture{
+		Organizations: []map[string]interface{}{{"id": testOrganizationId, "name": "Org One"}},
+	}
+	for _, name := range []string{"prod-1", "stage-1"} {
+		subscriptionId, clusterId := "sub-"+name, "cl-"+name
+		fixture.Subscriptions = append(fixture.Subscriptions, map[string]interface{}{
+			"id":              subscriptionId,
+			"cluster_id":      clusterId,
+			"display_name":    name,
+			"organization_id": testOrganizationId,
+			"managed":         true,
+			"status":          "Active",
+		})
+		fixture.Clusters = append(fixture.Clusters, map[string]interface{}{
+			"id": clusterId,
+			// policies refer to clusters by the display name of their subscription
+			"name":         "ocm-" + name,
+			"state":        "ready",
+			"managed":      true,
+			"subscription": map[string]interface{}{"id": subscriptionId},
+			"version":      map[string]interface{}{"id": "openshift-v4.14.0", "raw_id": "4.14.0"},
+		})
+	}
+	return fixture
+}
+
+// newFakeServer starts a fake OCM API server seeded with the given fixture and returns its URL.
+func newFakeServer(t *testing.T, fixture *fake.Fixture) (*fake.Server, string) {
+	t.Helper()
+	server, err := fake.NewServer(fixture)
+	if err != nil {
+		t.Fatalf("can't create fake server: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return server, httpServer.URL
+}
+
+// newFakeBackend creates a backend that is connected to a fake OCM API server seeded with the
+// given fixture.
+func newFakeBackend(t *testing.T, fixture *fake.Fixture) (PolicyBackend, *fake.Server) {
+	t.Helper()
+	server, url := newFakeServer(t, fixture)
+	connection, err := sdk.NewConnectionBuilder().
+		URL(url).
+		Tokens(ocm.UnsignedAccessToken("test-user")).
+		RetryLimit(0).
+		Build()
+	if err != nil {
+		t.Fatalf("can't create connection: %v", err)
+	}
+	t.Cleanup(func() { _ = connection.Close() })
+	backend, err := NewPolicyBackend("ocmlabels", connection)
+	if err != nil {
+		t.Fatalf("can't create backend: %v", err)
+	}
+	return backend, server
+}
+
+func subscriptionLabels(server *fake.Server, displayName string) map[string]interface{} {
+	for _, subscription := range server.Fixture().Subscriptions {
+		if subscription["display_name"] == displayName {
+			labels, _ := subscription["labels"].(map[string]string)
+			result := map[string]interface{}{}
+			for k, v := range labels {
+				if strings.HasPrefix(k, "sre-capabilities.aus.") {
+					result[strings.TrimPrefix(k, "sre-capabilities.aus.")] = v
+				}
+			}
+			return result
+		}
+	}
+	return nil
+}
+
+func testPolicy(clusterName string, soakDays int) policy.ClusterUpgradePolicy {
+	return policy.ClusterUpgradePolicy{
+		ClusterName: clusterName,
+		Schedule:    "0 10 * * 1-5",
+		Workloads:   []string{"w1"},
+		Conditions: policy.ClusterUpgradePolicyConditions{
+			SoakDays: &soakDays,
+			Sector:   "prod",
+		},
+	}
+}
+
+func TestApplyGetDeletePolicies(t *testing.T) {
+	ctx := context.Background()
+	backend, server := newFakeBackend(t, testFixture())
+
+	results, err := backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{
+		testPolicy("prod-1", 3),
+		testPolicy("stage-1", 0),
+	}, policy.ApplyOptions{})
+	if err != nil {
+		t.Fatalf("can't apply policies: %v", err)
+	}
+	if failed := policy.FailedApplyResults(results); len(failed) > 0 {
+		t.Fatalf("expected all policies to be applied, got %v", failed)
+	}
+	labels := subscriptionLabels(server, "prod-1")
+	if labels["schedule"] != "0 10 * * 1-5" || labels["soak-days"] != "3" || labels["sector"] != "prod" || labels["workloads"] != "w1" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	infos, err := backend.ListPolicies(ctx, testOrganizationId, false)
+	if err != nil {
+		t.Fatalf("can't list policies: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(infos))
+	}
+	p := infos["prod-1"].Policy
+	if p.ClusterName != "prod-1" || p.Schedule != "0 10 * * 1-5" || *p.Conditions.SoakDays != 3 || p.Conditions.Sector != "prod" {
+		t.Errorf("unexpected policy %+v", p)
+	}
+
+	// applying again updates the labels in place
+	_, err = backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{testPolicy("prod-1", 5)}, policy.ApplyOptions{})
+	if err != nil {
+		t.Fatalf("can't update policy: %v", err)
+	}
+	if labels := subscriptionLabels(server, "prod-1"); labels["soak-days"] != "5" {
+		t.Errorf("expected soak days to be updated, got %v", labels)
+	}
+
+	err = backend.DeletePolicy(ctx, testOrganizationId, "prod-1", policy.OwnerGuard{}, false)
+	if err != nil {
+		t.Fatalf("can't delete policy: %v", err)
+	}
+	if labels := subscriptionLabels(server, "prod-1"); len(labels) != 0 {
+		t.Errorf("expected all AUS labels to be deleted, got %v", labels)
+	}
+	infos, err = backend.ListPolicies(ctx, testOrganizationId, false)
+	if err != nil {
+		t.Fatalf("can't list policies: %v", err)
+	}
+	if _, ok := infos["prod-1"]; ok || len(infos) != 1 {
+		t.Errorf("expected only the stage-1 policy to remain, got %v", infos)
+	}
+
+	results, err = backend.DeletePolicies(ctx, testOrganizationId, []string{"stage-1"}, policy.OwnerGuard{}, false)
+	if err != nil || len(policy.FailedApplyResults(results)) > 0 {
+		t.Fatalf("can't delete policies: %v %v", err, results)
+	}
+	infos, err = backend.ListPolicies(ctx, testOrganizationId, true)
+	if err != nil {
+		t.Fatalf("can't list clusters: %v", err)
+	}
+	for name, info := range infos {
+		if info.Policy != nil && info.Policy.Validate() == nil {
+			t.Errorf("expected no policy for %s, got %+v", name, info.Policy)
+		}
+	}
+}
+
+func TestApplyPoliciesOwnership(t *testing.T) {
+	ctx := context.Background()
+	backend, server := newFakeBackend(t, testFixture())
+
+	owned := testPolicy("prod-1", 3)
+	owned.Owner = "team-a"
+	owned.Contact = "team-a@example.com"
+	owned.Ticket = "AUS-1"
+	results, err := backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{
+		owned,
+		testPolicy("stage-1", 0),
+	}, policy.ApplyOptions{OwnerGuard: policy.OwnerGuard{Team: "team-a"}})
+	if err != nil || len(policy.FailedApplyResults(results)) > 0 {
+		t.Fatalf("can't apply policies: %v %v", err, results)
+	}
+	labels := subscriptionLabels(server, "prod-1")
+	if labels["owner"] != "team-a" || labels["contact"] != "team-a@example.com" || labels["ticket"] != "AUS-1" {
+		t.Errorf("expected the ownership labels, got %v", labels)
+	}
+
+	// a policy without ownership keeps the ownership of the cluster
+	_, err = backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{testPolicy("prod-1", 5)}, policy.ApplyOptions{OwnerGuard: policy.OwnerGuard{Team: "team-a"}})
+	if err != nil {
+		t.Fatalf("can't update policy: %v", err)
+	}
+
+	infos, err := backend.ListPolicies(ctx, testOrganizationId, false)
+	if err != nil {
+		t.Fatalf("can't list policies: %v", err)
+	}
+	p := infos["prod-1"].Policy
+	if *p.Conditions.SoakDays != 5 || p.Owner != "team-a" || p.Contact != "team-a@example.com" || p.Ticket != "AUS-1" {
+		t.Errorf("expected the updated policy to keep its ownership, got %+v", p)
+	}
+	ownedBy := []string{}
+	for name, info := range infos {
+		if info.OwnedBy("team-a") {
+			ownedBy = append(ownedBy, name)
+		}
+	}
+	if !reflect.DeepEqual(ownedBy, []string{"prod-1"}) {
+		t.Errorf("expected only prod-1 to be owned by team-a, got %v", ownedBy)
+	}
+}
+
+func TestApplyPoliciesDryRun(t *testing.T) {
+	ctx := context.Background()
+	backend, server := newFakeBackend(t, testFixture())
+
+	_, err := backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{testPolicy("prod-1", 3)}, policy.ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("can't apply policies: %v", err)
+	}
+	if labels := subscriptionLabels(server, "prod-1"); len(labels) != 0 {
+		t.Errorf("expected a dry run to leave the labels untouched, got %v", labels)
+	}
+}
+
+func TestPolicyErrors(t *testing.T) {
+	ctx := context.Background()
+	backend, _ := newFakeBackend(t, testFixture())
+
+	_, err := backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{testPolicy("unknown", 0)}, policy.ApplyOptions{})
+	if err == nil {
+		t.Errorf("expected applying a policy to an unknown cluster to fail")
+	}
+	err = backend.DeletePolicy(ctx, testOrganizationId, "unknown", policy.OwnerGuard{}, false)
+	if err == nil {
+		t.Errorf("expected deleting the policy of an unknown cluster to fail")
+	}
+	_, err = backend.DeletePolicies(ctx, testOrganizationId, []string{"prod-1", "unknown"}, policy.OwnerGuard{}, false)
+	if err == nil {
+		t.Errorf("expected deleting the policies of an unknown cluster to fail")
+	}
+}
+
+func TestApplyPoliciesAtomicRollback(t *testing.T) {
+	original := map[string]interface{}{
+		"schedule":  "0 8 * * 1",
+		"workloads": "w0",
+		"soak-days": "1",
+		"sector":    "stage",
+	}
+	tests := []struct {
+		name string
+		// failures is the number of consecutive writes that fail, starting with the one after
+		// prod-1 and the first label of stage-1 have been written
+		failures            int
+		expectedRestored    int
+		expectedNotRestored int
+	}{
+		{
+			name:             "rollback",
+			failures:         1,
+			expectedRestored: 5,
+		},
+		{
+			// the first rollback step removes the label created on stage-1
+			name:                "failed rollback",
+			failures:            2,
+			expectedRestored:    4,
+			expectedNotRestored: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := testFixture()
+			labels := map[string]interface{}{}
+			for key, value := range original {
+				labels["sre-capabilities.aus."+key] = value
+			}
+			fixture.Subscriptions[0]["labels"] = labels
+			backend, server := newFakeBackend(t, fixture)
+
+			// prod-1 gets 4 changed labels, stage-1 4 new ones
+			server.FailWrites(6, tt.failures)
+			_, err := backend.ApplyPolicies(context.Background(), testOrganizationId, []policy.ClusterUpgradePolicy{
+				testPolicy("prod-1", 3),
+				testPolicy("stage-1", 0),
+			}, policy.ApplyOptions{Atomic: true})
+			var rollbackErr *ocmlabels.RollbackError
+			if !errors.As(err, &rollbackErr) {
+				t.Fatalf("expected a rollback error, got %v", err)
+			}
+			if len(rollbackErr.Restored) != tt.expectedRestored || len(rollbackErr.NotRestored) != tt.expectedNotRestored {
+				t.Fatalf("expected %d restored and %d not restored labels, got %v and %v",
+					tt.expectedRestored, tt.expectedNotRestored, rollbackErr.Restored, rollbackErr.NotRestored)
+			}
+			for key, value := range original {
+				restored := fmt.Sprintf("restored label sre-capabilities.aus.%s=%s on subscription sub-prod-1", key, value)
+				if !utils.StringInArray(rollbackErr.Restored, restored) {
+					t.Errorf("expected %q in the restored labels %v", restored, rollbackErr.Restored)
+				}
+			}
+			if !reflect.DeepEqual(subscriptionLabels(server, "prod-1"), original) {
+				t.Errorf("expected the original labels of prod-1, got %v", subscriptionLabels(server, "prod-1"))
+			}
+
+			stageLabels := subscriptionLabels(server, "stage-1")
+			if tt.expectedNotRestored == 0 {
+				if len(stageLabels) != 0 {
+					t.Errorf("expected the created labels of stage-1 to be removed, got %v", stageLabels)
+				}
+				return
+			}
+			if len(stageLabels) != 1 {
+				t.Fatalf("expected the label that couldn't be removed to remain on stage-1, got %v", stageLabels)
+			}
+			for key := range stageLabels {
+				if !strings.Contains(rollbackErr.NotRestored[0], "removed label sre-capabilities.aus."+key+" from subscription sub-stage-1") {
+					t.Errorf("expected the failed rollback of %s to be reported, got %v", key, rollbackErr.NotRestored)
+				}
+			}
+			if !strings.Contains(err.Error(), "failed to roll back") {
+				t.Errorf("expected the error to mention the failed rollback, got %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyPoliciesPartialFailure(t *testing.T) {
+	invalid := testPolicy("stage-1", 0)
+	invalid.Schedule = ""
+	tests := []struct {
+		name          string
+		stageLabels   map[string]interface{}
+		stagePolicy   policy.ClusterUpgradePolicy
+		atomic        bool
+		expectedError string
+	}{
+		{
+			name:          "invalid policy",
+			stagePolicy:   invalid,
+			expectedError: "schedule is required",
+		},
+		{
+			name:          "policy owned by another team",
+			stageLabels:   map[string]interface{}{"sre-capabilities.aus.owner": "team-b"},
+			stagePolicy:   testPolicy("stage-1", 0),
+			expectedError: "owned by team team-b",
+		},
+		{
+			name:          "atomic apply with an invalid policy",
+			stagePolicy:   invalid,
+			atomic:        true,
+			expectedError: "schedule is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := testFixture()
+			if tt.stageLabels != nil {
+				fixture.Subscriptions[1]["labels"] = tt.stageLabels
+			}
+			backend, server := newFakeBackend(t, fixture)
+
+			results, err := backend.ApplyPolicies(context.Background(), testOrganizationId, []policy.ClusterUpgradePolicy{
+				testPolicy("prod-1", 3),
+				tt.stagePolicy,
+			}, policy.ApplyOptions{Atomic: tt.atomic, OwnerGuard: policy.OwnerGuard{Team: "team-a"}})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if len(results) != 2 || results[0].ClusterName != "prod-1" || results[1].ClusterName != "stage-1" {
+				t.Fatalf("expected a result for each cluster in order, got %v", results)
+			}
+			if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), tt.expectedError) {
+				t.Errorf("expected the stage-1 result to fail with %q, got %v", tt.expectedError, results[1].Err)
+			}
+			prodLabels := subscriptionLabels(server, "prod-1")
+			if tt.atomic {
+				if results[0].Err == nil {
+					t.Errorf("expected the prod-1 result to report the aborted apply")
+				}
+				if len(prodLabels) != 0 {
+					t.Errorf("expected an aborted atomic apply to leave prod-1 untouched, got %v", prodLabels)
+				}
+				return
+			}
+			if results[0].Err != nil {
+				t.Errorf("expected prod-1 to be applied, got %v", results[0].Err)
+			}
+			if prodLabels["soak-days"] != "3" || prodLabels["schedule"] != "0 10 * * 1-5" {
+				t.Errorf("expected the policy of prod-1 to be applied, got %v", prodLabels)
+			}
+			if labels := subscriptionLabels(server, "stage-1"); len(labels) != len(tt.stageLabels) {
+				t.Errorf("expected stage-1 to be left untouched, got %v", labels)
+			}
+		})
+	}
+}
+
+func TestBackendWithProfileConnection(t *testing.T) {
+	server, url := newFakeServer(t, testFixture())
+	profiles, err := json.Marshal(map[string]interface{}{
+		"profiles": map[string]interface{}{
+			"fake": map[string]string{"url": url, "token": ocm.UnsignedAccessToken("test-user")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("can't marshal profiles: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, profiles, 0600); err != nil {
+		t.Fatalf("can't write profiles: %v", err)
+	}
+	t.Setenv("OCM_AUS_PROFILES", path)
+	options := ocm.DefaultConnectionOptions()
+	options.Profile = "fake"
+	ocm.SetConnectionOptions(options)
+	t.Cleanup(func() { ocm.SetConnectionOptions(ocm.DefaultConnectionOptions()) })
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		t.Fatalf("can't create connection: %v", err)
+	}
+	defer connection.Close()
+	backend, err := NewPolicyBackend("", connection)
+	if err != nil {
+		t.Fatalf("can't create backend: %v", err)
+	}
+
+	// the organization defaults to the one of the current account
+	_, err = backend.ApplyPolicies(context.Background(), "", []policy.ClusterUpgradePolicy{testPolicy("prod-1", 3)}, policy.ApplyOptions{})
+	if err != nil {
+		t.Fatalf("can't apply policies: %v", err)
+	}
+	if labels := subscriptionLabels(server, "prod-1"); labels["soak-days"] != "3" {
+		t.Errorf("expected the policy to be applied, got %v", labels)
+	}
+}
+
+func TestBackendCanceledContext(t *testing.T) {
+	backend, server := newFakeBackend(t, testFixture())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := backend.ListPolicies(ctx, testOrganizationId, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected listing policies to be canceled, got %v", err)
+	}
+	_, err = backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{testPolicy("prod-1", 3)}, policy.ApplyOptions{})
+	if err == nil {
+		t.Errorf("expected applying policies to be canceled")
+	}
+	if labels := subscriptionLabels(server, "prod-1"); len(labels) != 0 {
+		t.Errorf("expected a canceled apply to leave the labels untouched, got %v", labels)
+	}
+}
+
+func TestNewPolicyBackendUnknownType(t *testing.T) {
+	_, err := NewPolicyBackend("database", nil)
+	if err == nil {
+		t.Errorf("expected an unknown backend type to fail")
+	}
+}
+
+func TestApplyPoliciesCanaries(t *testing.T) {
+	canaryLabels := map[string]interface{}{
+		"sre-capabilities.aus.schedule":  "0 10 * * 1-5",
+		"sre-capabilities.aus.workloads": "w1",
+		"sre-capabilities.aus.soak-days": "0",
+		"sre-capabilities.aus.canary":    "true",
+	}
+	canary := testPolicy("prod-1", 0)
+	canary.Conditions.Canary = true
+
+	tests := []struct {
+		name        string
+		labels      map[string]interface{}
+		policies    []policy.ClusterUpgradePolicy
+		expectedErr bool
+	}{
+		{
+			name:        "second canary",
+			labels:      canaryLabels,
+			policies:    []policy.ClusterUpgradePolicy{canary},
+			expectedErr: true,
+		},
+		{
+			name:   "removed canary",
+			labels: canaryLabels,
+			policies: []policy.ClusterUpgradePolicy{
+				{ClusterName: "stage-1", Schedule: "0 10 * * 1-5", Workloads: []string{"w1"}},
+			},
+			expectedErr: true,
+		},
+		{
+			name:   "moved canary",
+			labels: canaryLabels,
+			policies: []policy.ClusterUpgradePolicy{
+				canary,
+				{ClusterName: "stage-1", Schedule: "0 10 * * 1-5", Workloads: []string{"w1"}},
+			},
+		},
+		{
+			name: "invalid policy of another cluster",
+			labels: map[string]interface{}{
+				"sre-capabilities.aus.workloads": "w1",
+				"sre-capabilities.aus.soak-days": "many",
+				"sre-capabilities.aus.canary":    "true",
+			},
+			policies: []policy.ClusterUpgradePolicy{canary},
+		},
+		{
+			name:     "invalid canary label of another cluster",
+			labels:   map[string]interface{}{"sre-capabilities.aus.canary": "yes please"},
+			policies: []policy.ClusterUpgradePolicy{canary},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := testFixture()
+			fixture.Subscriptions[1]["labels"] = tt.labels
+			backend, _ := newFakeBackend(t, fixture)
+			_, err := backend.ApplyPolicies(context.Background(), testOrganizationId, tt.policies, policy.ApplyOptions{})
+			if tt.expectedErr && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !tt.expectedErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestListPoliciesHeldBackByCanary(t *testing.T) {
+	policyLabels := func(canary bool) map[string]interface{} {
+		return map[string]interface{}{
+			"sre-capabilities.aus.schedule":  "0 10 * * 1-5",
+			"sre-capabilities.aus.workloads": "w1",
+			"sre-capabilities.aus.soak-days": "0",
+			"sre-capabilities.aus.canary":    strconv.FormatBool(canary),
+		}
+	}
+	tests := []struct {
+		name             string
+		canaryVersion    string
+		expectedHeldBack []string
+	}{
+		{
+			name:             "canary behind",
+			canaryVersion:    "4.14.0",
+			expectedHeldBack: []string{"4.14.1", "4.15.0"},
+		},
+		{
+			name:             "canary ahead",
+			canaryVersion:    "4.14.1",
+			expectedHeldBack: []string{"4.15.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := testFixture()
+			fixture.Subscriptions[0]["labels"] = policyLabels(false)
+			fixture.Subscriptions[1]["labels"] = policyLabels(true)
+			fixture.Clusters[0]["version"] = map[string]interface{}{
+				"id":                 "openshift-v4.14.0",
+				"raw_id":             "4.14.0",
+				"available_upgrades": []string{"4.14.1", "4.15.0"},
+			}
+			fixture.Clusters[1]["version"] = map[string]interface{}{"id": "openshift-v" + tt.canaryVersion, "raw_id": tt.canaryVersion}
+			backend, _ := newFakeBackend(t, fixture)
+
+			infos, err := backend.ListPolicies(context.Background(), testOrganizationId, false)
+			if err != nil {
+				t.Fatalf("can't list policies: %v", err)
+			}
+			if heldBack := infos["prod-1"].HeldBackUpgrades(); !reflect.DeepEqual(heldBack, tt.expectedHeldBack) {
+				t.Errorf("expected held back upgrades %v, got %v", tt.expectedHeldBack, heldBack)
+			}
+			if heldBack := infos["stage-1"].HeldBackUpgrades(); len(heldBack) != 0 {
+				t.Errorf("expected the canary not to wait for itself, got %v", heldBack)
+			}
+		})
+	}
+}
+
+func TestListPoliciesWithInvalidLabels(t *testing.T) {
+	fixture := testFixture()
+	fixture.Organizations[0]["labels"] = map[string]interface{}{
+		"sre-capabilities.aus.sector-approval.prod.4.14.1": "someone",
+		"sre-capabilities.aus.sector-approval.prod":        "someone,2026-01-01T00:00:00Z",
+		"sre-capabilities.aus.sector-approval.prod.4.14.2": "someone,2026-01-01T00:00:00Z",
+	}
+	fixture.Subscriptions[0]["labels"] = map[string]interface{}{
+		"sre-capabilities.aus.schedule":        "0 10 * * 1-5",
+		"sre-capabilities.aus.workloads":       "w1",
+		"sre-capabilities.aus.soak-days":       "0",
+		"sre-capabilities.aus.sector":          "prod",
+		"sre-capabilities.aus.approval.4.15.0": "someone,yesterday",
+	}
+	fixture.Subscriptions[1]["labels"] = map[string]interface{}{
+		"sre-capabilities.aus.schedule":  "0 10 * * 1-5",
+		"sre-capabilities.aus.workloads": "w1",
+		"sre-capabilities.aus.soak-days": "many",
+	}
+	backend, _ := newFakeBackend(t, fixture)
+
+	infos, err := backend.ListPolicies(context.Background(), testOrganizationId, false)
+	if err != nil {
+		t.Fatalf("expected invalid labels to be skipped, got %v", err)
+	}
+	if _, ok := infos["prod-1"]; !ok || len(infos) != 1 {
+		t.Fatalf("expected only the valid policy of prod-1, got %v", infos)
+	}
+	approvals := infos["prod-1"].Approvals
+	if len(approvals) != 1 || approvals[0].Version != "4.14.2" || approvals[0].Sector != "prod" {
+		t.Errorf("expected only the valid sector approval, got %+v", approvals)
+	}
+
+	_, clusterInfos, _, _, _, err := backend.Status(context.Background(), testOrganizationId, true)
+	if err != nil {
+		t.Fatalf("expected the status to skip invalid labels, got %v", err)
+	}
+	if len(clusterInfos) != 2 {
+		t.Errorf("expected both clusters in the status, got %d", len(clusterInfos))
+	}
+}
+
+func TestDeleteSector(t *testing.T) {
+	tests := []struct {
+		name          string
+		force         bool
+		dependent     bool
+		expectedError string
+	}{
+		{
+			name:          "depended on by another sector",
+			force:         true,
+			dependent:     true,
+			expectedError: "sector stage depends on sector prod",
+		},
+		{
+			name:          "used by policies",
+			expectedError: "sector prod is used by the policies of clusters prod-1, stage-1",
+		},
+		{
+			name:  "forced",
+			force: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := testFixture()
+			fixture.Organizations[0]["labels"] = map[string]interface{}{
+				"sre-capabilities.aus.sector-max-parallel-upgrades.prod": "1",
+			}
+			if tt.dependent {
+				fixture.Organizations[0]["labels"].(map[string]interface{})["sre-capabilities.aus.sector-deps.stage"] = "prod"
+			}
+			backend, _ := newFakeBackend(t, fixture)
+			ctx := context.Background()
+			_, err := backend.ApplyPolicies(ctx, testOrganizationId, []policy.ClusterUpgradePolicy{
+				testPolicy("prod-1", 3),
+				testPolicy("stage-1", 0),
+			}, policy.ApplyOptions{})
+			if err != nil {
+				t.Fatalf("can't apply policies: %v", err)
+			}
+
+			// the check fails like the deletion, but never deletes
+			checkErr := backend.CheckDeleteSector(ctx, testOrganizationId, "prod", tt.force)
+			if (checkErr == nil) != (tt.expectedError == "") || (checkErr != nil && !strings.Contains(checkErr.Error(), tt.expectedError)) {
+				t.Errorf("expected the check to fail with %q, got %v", tt.expectedError, checkErr)
+			}
+			if sectors, _ := backend.ListSectorConfiguration(ctx, testOrganizationId); len(sectors) == 0 {
+				t.Fatalf("expected the check to keep the sector")
+			}
+
+			err = backend.DeleteSector(ctx, testOrganizationId, "prod", tt.force, false)
+			sectors, listErr := backend.ListSectorConfiguration(ctx, testOrganizationId)
+			if listErr != nil {
+				t.Fatalf("can't list sectors: %v", listErr)
+			}
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				if len(sectors) == 0 {
+					t.Errorf("expected the sector to be kept, got %v", sectors)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(sectors) != 0 {
+				t.Errorf("expected the sector to be deleted, got %v", sectors)
+			}
+		})
+	}
+}
+
+func TestVersionDataInheritanceRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	backend, server := newFakeBackend(t, testFixture())
+
+	config := versiondata.VersionDataInheritanceConfig{
+		InheritingFromOrgs: []string{"org-a", "org-b"},
+		PublishingToOrgs:   []string{"org-c"},
+		InheritWorkloads:   map[string][]string{"org-a": {"w1", "w2"}},
+		PublishWorkloads:   map[string][]string{"org-c": {"w1"}},
+	}
+	err := backend.ApplyVersionDataInheritanceConfiguration(ctx, testOrganizationId, config, false, false)
+	if err != nil {
+		t.Fatalf("can't apply inheritance: %v", err)
+	}
+	labels := server.Fixture().Organizations[0]["labels"].(map[string]string)
+	expectedLabels := map[string]string{
+		"sre-capabilities.aus.version-data.inherit":                 "org-a,org-b",
+		"sre-capabilities.aus.version-data.publish":                 "org-c",
+		"sre-capabilities.aus.version-data.inherit-workloads.org-a": "w1,w2",
+		"sre-capabilities.aus.version-data.publish-workloads.org-c": "w1",
+	}
+	if !reflect.DeepEqual(labels, expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, labels)
+	}
+	current, err := backend.GetVersionDataInheritanceConfiguration(ctx, testOrganizationId)
+	if err != nil {
+		t.Fatalf("can't get inheritance: %v", err)
+	}
+	if !reflect.DeepEqual(current, config) {
+		t.Errorf("expected %+v, got %+v", config, current)
+	}
+
+	// removing an organization removes its workload filter label as well
+	removed, err := versiondata.NewRemovedVersionDataInheritanceConfigFromEntries([]string{"org-a"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	consolidated := versiondata.ConsolidateVersionDataInheritanceConfig(current, versiondata.VersionDataInheritanceConfig{}, removed)
+	err = backend.ApplyVersionDataInheritanceConfiguration(ctx, testOrganizationId, consolidated, false, false)
+	if err != nil {
+		t.Fatalf("can't apply inheritance: %v", err)
+	}
+	labels = server.Fixture().Organizations[0]["labels"].(map[string]string)
+	if _, ok := labels["sre-capabilities.aus.version-data.inherit-workloads.org-a"]; ok || labels["sre-capabilities.aus.version-data.inherit"] != "org-b" {
+		t.Errorf("expected org-a and its workload filter to be removed, got %v", labels)
+	}
+}
+
+// TestStatusReplay runs Status against a recording made with --record against the fake server.
+func TestStatusReplay(t *testing.T) {
+	options := ocm.DefaultConnectionOptions()
+	options.ReplayDir = filepath.Join("testdata", "status-recording")
+	ocm.SetConnectionOptions(options)
+	t.Cleanup(func() { ocm.SetConnectionOptions(ocm.DefaultConnectionOptions()) })
+
+	connection, err := ocm.NewOCMConnection()
+	if err != nil {
+		t.Fatalf("can't create replay connection: %v", err)
+	}
+	defer connection.Close()
+	backend, err := NewPolicyBackend("ocmlabels", connection)
+	if err != nil {
+		t.Fatalf("can't create backend: %v", err)
+	}
+
+	organization, clusterInfos, blockedVersions, sectors, inheritance, err := backend.Status(context.Background(), "", true)
+	if err != nil {
+		t.Fatalf("can't replay status: %v", err)
+	}
+	if organization.ID() != testOrganizationId || organization.Name() != "Org One" {
+		t.Errorf("unexpected organization %s (%s)", organization.ID(), organization.Name())
+	}
+	if len(clusterInfos) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusterInfos))
+	}
+	for _, clusterInfo := range clusterInfos {
+		if clusterInfo.DisplayName() != "prod-1" {
+			continue
+		}
+		p := clusterInfo.Policy
+		if p == nil || p.Schedule != "0 10 * * 1-5" || *p.Conditions.SoakDays != 3 || p.Conditions.Sector != "prod" || p.Owner != "team-a" {
+			t.Errorf("unexpected policy of prod-1 %+v", p)
+		}
+	}
+	if !reflect.DeepEqual(blockedVersions, []string{`^4\.15\..*$`}) {
+		t.Errorf("unexpected blocked versions %v", blockedVersions)
+	}
+	if len(sectors) != 1 || sectors[0].Name != "prod" || sectors[0].MaxParallelUpgrades != "1" {
+		t.Errorf("unexpected sectors %+v", sectors)
+	}
+	if !reflect.DeepEqual(inheritance.InheritingFromOrgs, []string{"org2"}) {
+		t.Errorf("unexpected inheritance %+v", inheritance)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/versiondata"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// NewInheritanceLookup returns a lookup of version data inheritance configurations that finds
+// organizations in every OCM environment known to the connection manager.
+func NewInheritanceLookup(ctx context.Context, backendType string, connections *ocm.ConnectionManager) versiondata.InheritanceLookup {
+	var mutex sync.Mutex
+	backends := map[*sdk.Connection]PolicyBackend{}
+	return func(organizationId string) (*versiondata.OrganizationInheritance, error) {
+		connection, organization, err := connections.ForOrganization(ctx, organizationId)
+		if err != nil {
+			return nil, err
+		}
+		mutex.Lock()
+		be, ok := backends[connection]
+		if !ok {
+			be, err = NewPolicyBackend(backendType, connection)
+			if err == nil {
+				backends[connection] = be
+			}
+		}
+		mutex.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		config, err := be.GetVersionDataInheritanceConfiguration(ctx, organizationId)
+		if err != nil {
+			return nil, err
+		}
+		return &versiondata.OrganizationInheritance{
+			OrganizationId: organizationId,
+			Name:           organization.Name(),
+			Environment:    connection.URL(),
+			Config:         config,
+		}, nil
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocmlabels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// approvals of a cluster are stored as approval.<version> subscription labels, approvals of a sector
+// as sector-approval.<sector>.<version> organization labels
+var APPROVAL_LABEL_KEY_PREFIX = newAusLabelKey("approval.")
+var SECTOR_APPROVAL_LABEL_KEY_PREFIX = newAusLabelKey("sector-approval.")
+
+func (f *OCMLabelsPolicyBackend) ApproveClusterVersion(ctx context.Context, organizationId string, clusterName string, approval policy.Approval, dryRun bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	subscription, err := ocm.SubscriptionForDisplayName(ctx, organizationId, clusterName, f.connection)
+	if err != nil {
+		return err
+	}
+	label, err := buildOCMLabel(APPROVAL_LABEL_KEY_PREFIX+approval.Version, approval.String(), subscription.ID(), "")
+	if err != nil {
+		return err
+	}
+	output.Log(dryRun, "Approve version %s for cluster %s\n", approval.Version, clusterName)
+	_, err = applyOCMLabel(ctx, label, dryRun, f.connection)
+	return err
+}
+
+func (f *OCMLabelsPolicyBackend) ApproveSectorVersion(ctx context.Context, organizationId string, approval policy.Approval, dryRun bool) error {
+	if strings.Contains(approval.Sector, ".") {
+		return fmt.Errorf("versions can't be approved for sector %s, sector names with dots are not supported", approval.Sector)
+	}
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	label, err := buildOCMLabel(fmt.Sprintf("%s%s.%s", SECTOR_APPROVAL_LABEL_KEY_PREFIX, approval.Sector, approval.Version), approval.String(), "", organizationId)
+	if err != nil {
+		return err
+	}
+	output.Log(dryRun, "Approve version %s for sector %s in organization %s\n", approval.Version, approval.Sector, organizationId)
+	_, err = applyOCMLabel(ctx, label, dryRun, f.connection)
+	return err
+}
+
+// getSectorApprovals returns the approvals of all sectors, by sector name.
+func getSectorApprovals(ctx context.Context, organizationId string, connection *sdk.Connection) (map[string][]policy.Approval, error) {
+	labels, err := listOrganizationLabels(ctx, organizationId, SECTOR_APPROVAL_LABEL_KEY_PREFIX, connection)
+	if err != nil {
+		return nil, err
+	}
+	approvals := map[string][]policy.Approval{}
+	for _, label := range labels {
+		sector, version, ok := strings.Cut(strings.TrimPrefix(label.Key(), SECTOR_APPROVAL_LABEL_KEY_PREFIX), ".")
+		if !ok {
+			warn("ignoring the invalid sector approval label %s", label.Key())
+			continue
+		}
+		approval, err := policy.ParseApproval(version, sector, label.Value())
+		if err != nil {
+			warn("ignoring the approval for sector %s: %v", sector, err)
+			continue
+		}
+		approvals[sector] = append(approvals[sector], approval)
+	}
+	return approvals, nil
+}
+
+// getSubscriptionApprovals returns the approvals of a cluster. Invalid approvals are skipped, so
+// they don't approve anything.
+func getSubscriptionApprovals(subscription *amv1.Subscription) []policy.Approval {
+	approvals := []policy.Approval{}
+	for _, label := range filterLabels(subscription.Labels(), APPROVAL_LABEL_KEY_PREFIX) {
+		approval, err := policy.ParseApproval(strings.TrimPrefix(label.Key(), APPROVAL_LABEL_KEY_PREFIX), "", label.Value())
+		if err != nil {
+			warn("ignoring the approval for cluster %s: %v", subscription.DisplayName(), err)
+			continue
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals
+}
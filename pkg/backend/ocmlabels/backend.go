@@ -16,9 +16,27 @@ limitations under the License.
 
 package ocmlabels
 
+import (
+	"context"
+
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
 type OCMLabelsPolicyBackend struct {
+	connection *sdk.Connection
+}
+
+func NewOCMLabelsPolicyBackend(connection *sdk.Connection) *OCMLabelsPolicyBackend {
+	return &OCMLabelsPolicyBackend{
+		connection: connection,
+	}
 }
 
-func NewOCMLabelsPolicyBackend() *OCMLabelsPolicyBackend {
-	return &OCMLabelsPolicyBackend{}
+// organizationId returns the given organization ID or the one of the logged in user if it is empty.
+func (f *OCMLabelsPolicyBackend) organizationId(ctx context.Context, organizationId string) (string, error) {
+	if organizationId != "" {
+		return organizationId, nil
+	}
+	return ocm.CurrentOrganizationId(ctx, f.connection)
 }
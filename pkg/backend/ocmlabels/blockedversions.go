@@ -17,34 +17,29 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 
-	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/app-sre/aus-cli/pkg/utils"
 	"github.com/app-sre/aus-cli/pkg/versions"
 	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
-func (f *OCMLabelsPolicyBackend) ListBlockedVersionExpressions(organizationId string) ([]string, error) {
-	connection, err := ocm.NewOCMConnection()
+func (f *OCMLabelsPolicyBackend) ListBlockedVersionExpressions(ctx context.Context, organizationId string) ([]string, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return nil, err
 	}
 
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return getBlockedVersionsForOrganization(organizationId, connection)
+	return getBlockedVersionsForOrganization(ctx, organizationId, f.connection)
 }
 
-func (f *OCMLabelsPolicyBackend) ApplyBlockedVersionExpressions(organizationId string, blockExpressions []string, dumpVersionBlocks bool, dryRun bool) error {
+func (f *OCMLabelsPolicyBackend) ApplyBlockedVersionExpressions(ctx context.Context, organizationId string, blockExpressions []string, dumpVersionBlocks bool, dryRun bool) error {
 	if dumpVersionBlocks {
 		body, err := json.Marshal(blockExpressions)
 		if err != nil {
@@ -57,32 +52,59 @@ func (f *OCMLabelsPolicyBackend) ApplyBlockedVersionExpressions(organizationId s
 		return nil
 	}
 
-	connection, err := ocm.NewOCMConnection()
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return err
 	}
 
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return err
-		}
-	}
-
 	output.Log(dryRun, "Apply blocked version labels to organization %s\n", organizationId)
 	label, err := buildOCMLabel(newAusLabelKey("blocked-versions"), utils.StringArrayToCSV(blockExpressions), "", organizationId)
 	if err != nil {
 		return err
 	}
-	err = applyOCMLabel(label, dryRun, connection)
+	_, err = applyOCMLabel(ctx, label, dryRun, f.connection)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func getBlockedVersionsForOrganization(organizationId string, connection *sdk.Connection) ([]string, error) {
-	label, err := getOrganizationLabel(organizationId, newAusLabelKey("blocked-versions"), connection)
+func (f *OCMLabelsPolicyBackend) DeleteBlockedVersionExpressions(ctx context.Context, organizationId string, blockExpressions []string, dryRun bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	label, err := getOrganizationLabel(ctx, organizationId, newAusLabelKey("blocked-versions"), f.connection)
+	if err != nil {
+		return err
+	}
+	if label == nil {
+		return fmt.Errorf("organization %s has no blocked versions", organizationId)
+	}
+	current := strings.Split(label.Value(), ",")
+	normalizedCurrent := versions.ConsolidateVersionBlocks(current, nil, nil)
+	for _, expression := range versions.ConsolidateVersionBlocks(blockExpressions, nil, nil) {
+		if !utils.StringInArray(normalizedCurrent, expression) {
+			return fmt.Errorf("version expression %s is not blocked in organization %s", expression, organizationId)
+		}
+	}
+	remaining := versions.SortVersionExpressions(versions.ConsolidateVersionBlocks(current, nil, blockExpressions))
+
+	output.Log(dryRun, "Delete blocked versions %s from organization %s\n", strings.Join(blockExpressions, ", "), organizationId)
+	labelsContainer := NewOCMLabelsContainer([]*amv1.Label{label})
+	if len(remaining) > 0 {
+		remainingLabel, err := buildOCMLabel(newAusLabelKey("blocked-versions"), utils.StringArrayToCSV(remaining), "", organizationId)
+		if err != nil {
+			return err
+		}
+		labelsContainer.AddLabel(remainingLabel)
+	}
+	return labelsContainer.Reconcile(ctx, dryRun, f.connection)
+}
+
+func getBlockedVersionsForOrganization(ctx context.Context, organizationId string, connection *sdk.Connection) ([]string, error) {
+	label, err := getOrganizationLabel(ctx, organizationId, newAusLabelKey("blocked-versions"), connection)
 	if err != nil {
 		return nil, err
 	}
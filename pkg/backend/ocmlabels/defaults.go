@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocmlabels
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	"github.com/app-sre/aus-cli/pkg/utils"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+var POLICY_DEFAULTS_LABEL_KEY_PREFIX = newAusLabelKey("policy-defaults.")
+var DEFAULT_SCHEDULE_LABEL_KEY = POLICY_DEFAULTS_LABEL_KEY_PREFIX + "schedule"
+var DEFAULT_SOAK_DAYS_LABEL_KEY = POLICY_DEFAULTS_LABEL_KEY_PREFIX + "soak-days"
+var DEFAULT_SECTOR_LABEL_KEY = POLICY_DEFAULTS_LABEL_KEY_PREFIX + "sector"
+var DEFAULT_MUTEXES_LABEL_KEY = POLICY_DEFAULTS_LABEL_KEY_PREFIX + "mutexes"
+
+func (f *OCMLabelsPolicyBackend) GetPolicyDefaults(ctx context.Context, organizationId string) (policy.PolicyDefaults, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return policy.PolicyDefaults{}, err
+	}
+	return getPolicyDefaults(ctx, organizationId, f.connection)
+}
+
+func (f *OCMLabelsPolicyBackend) ApplyPolicyDefaults(ctx context.Context, organizationId string, defaults policy.PolicyDefaults, dumpDefaults bool, dryRun bool) error {
+	if dumpDefaults {
+		body, err := json.Marshal(defaults)
+		if err != nil {
+			return err
+		}
+		return output.PrettyList(os.Stdout, body)
+	}
+
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	output.Log(dryRun, "Apply policy defaults to organization %s\n", organizationId)
+	currentLabels, err := listOrganizationLabels(ctx, organizationId, POLICY_DEFAULTS_LABEL_KEY_PREFIX, f.connection)
+	if err != nil {
+		return err
+	}
+	labelsContainer := NewOCMLabelsContainer(currentLabels)
+	values := map[string]string{
+		DEFAULT_SCHEDULE_LABEL_KEY: defaults.Schedule,
+		DEFAULT_SECTOR_LABEL_KEY:   defaults.Sector,
+		DEFAULT_MUTEXES_LABEL_KEY:  utils.StringArrayToCSV(defaults.Mutexes),
+	}
+	if defaults.SoakDays != nil {
+		values[DEFAULT_SOAK_DAYS_LABEL_KEY] = strconv.Itoa(*defaults.SoakDays)
+	}
+	for key, value := range values {
+		if value == "" {
+			continue
+		}
+		label, err := buildOCMLabel(key, value, "", organizationId)
+		if err != nil {
+			return err
+		}
+		labelsContainer.AddLabel(label)
+	}
+	return labelsContainer.Reconcile(ctx, dryRun, f.connection)
+}
+
+func getPolicyDefaults(ctx context.Context, organizationId string, connection *sdk.Connection) (policy.PolicyDefaults, error) {
+	labels, err := listOrganizationLabels(ctx, organizationId, POLICY_DEFAULTS_LABEL_KEY_PREFIX, connection)
+	if err != nil {
+		return policy.PolicyDefaults{}, err
+	}
+	return newPolicyDefaultsFromOCMLabels(labels)
+}
+
+func newPolicyDefaultsFromOCMLabels(labels []*amv1.Label) (policy.PolicyDefaults, error) {
+	defaults := policy.PolicyDefaults{}
+	for _, label := range labels {
+		switch label.Key() {
+		case DEFAULT_SCHEDULE_LABEL_KEY:
+			defaults.Schedule = label.Value()
+		case DEFAULT_SOAK_DAYS_LABEL_KEY:
+			soakDays, err := strconv.Atoi(label.Value())
+			if err != nil {
+				return defaults, err
+			}
+			defaults.SoakDays = &soakDays
+		case DEFAULT_SECTOR_LABEL_KEY:
+			defaults.Sector = label.Value()
+		case DEFAULT_MUTEXES_LABEL_KEY:
+			defaults.Mutexes = strings.Split(label.Value(), ",")
+		}
+	}
+	return defaults, nil
+}
@@ -17,11 +17,12 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 
-	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/app-sre/aus-cli/pkg/utils"
 	"github.com/app-sre/aus-cli/pkg/versiondata"
@@ -31,22 +32,20 @@ import (
 var INHERIT_LABEL_KEY = newAusLabelKey("version-data.inherit")
 var PUBLISH_LABEL_KEY = newAusLabelKey("version-data.publish")
 
-func (f *OCMLabelsPolicyBackend) GetVersionDataInheritanceConfiguration(organizationId string) (versiondata.VersionDataInheritanceConfig, error) {
-	connection, err := ocm.NewOCMConnection()
+// Workload filters are stored in separate labels per organization, so that the inherit and
+// publish labels keep their plain list format.
+var INHERIT_WORKLOADS_LABEL_PREFIX = newAusLabelKey("version-data.inherit-workloads.")
+var PUBLISH_WORKLOADS_LABEL_PREFIX = newAusLabelKey("version-data.publish-workloads.")
+
+func (f *OCMLabelsPolicyBackend) GetVersionDataInheritanceConfiguration(ctx context.Context, organizationId string) (versiondata.VersionDataInheritanceConfig, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return versiondata.VersionDataInheritanceConfig{}, err
 	}
-
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return versiondata.VersionDataInheritanceConfig{}, err
-		}
-	}
-	return listVersionDataInheritanceConfiguration(organizationId, connection)
+	return listVersionDataInheritanceConfiguration(ctx, organizationId, f.connection)
 }
 
-func (f *OCMLabelsPolicyBackend) ApplyVersionDataInheritanceConfiguration(organizationId string, inheritance versiondata.VersionDataInheritanceConfig, dumpConfig bool, dryRun bool) error {
+func (f *OCMLabelsPolicyBackend) ApplyVersionDataInheritanceConfiguration(ctx context.Context, organizationId string, inheritance versiondata.VersionDataInheritanceConfig, dumpConfig bool, dryRun bool) error {
 	if dumpConfig {
 		body, err := json.Marshal(inheritance)
 		if err != nil {
@@ -59,21 +58,18 @@ func (f *OCMLabelsPolicyBackend) ApplyVersionDataInheritanceConfiguration(organi
 		return nil
 	}
 
-	connection, err := ocm.NewOCMConnection()
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return err
 	}
 
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return err
-		}
+	if len(inheritance.InheritingFromOrgs) == 0 && len(inheritance.PublishingToOrgs) == 0 {
+		output.Log(dryRun, "Remove version data inheritance configuration from organization %s\n", organizationId)
+	} else {
+		output.Log(dryRun, "Apply version data inheritance configuration to organization %s\n", organizationId)
 	}
 
-	output.Log(dryRun, "Apply version data inheritance configuration to organization %s\n", organizationId)
-
-	labels, err := listOrganizationLabels(organizationId, newAusLabelKey("version-data."), connection)
+	labels, err := listOrganizationLabels(ctx, organizationId, newAusLabelKey("version-data."), f.connection)
 	if err != nil {
 		return err
 	}
@@ -99,26 +95,73 @@ func (f *OCMLabelsPolicyBackend) ApplyVersionDataInheritanceConfiguration(organi
 		labelsContainer.AddLabel(publishLabel)
 	}
 
-	return labelsContainer.Reconcile(dryRun, connection)
+	for prefix, filters := range map[string]map[string][]string{
+		INHERIT_WORKLOADS_LABEL_PREFIX: inheritance.InheritWorkloads,
+		PUBLISH_WORKLOADS_LABEL_PREFIX: inheritance.PublishWorkloads,
+	} {
+		for orgId, workloads := range filters {
+			workloadsLabel, err := buildOCMLabel(
+				prefix+orgId, utils.StringArrayToCSV(workloads), "", organizationId,
+			)
+			if err != nil {
+				return err
+			}
+			labelsContainer.AddLabel(workloadsLabel)
+		}
+	}
+
+	return labelsContainer.Reconcile(ctx, dryRun, f.connection)
 }
 
-func listVersionDataInheritanceConfiguration(organizationId string, connection *sdk.Connection) (versiondata.VersionDataInheritanceConfig, error) {
-	labels, err := listOrganizationLabels(organizationId, newAusLabelKey("version-data."), connection)
+func (f *OCMLabelsPolicyBackend) DeleteVersionDataInheritanceConfiguration(ctx context.Context, organizationId string, dryRun bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	labels, err := listOrganizationLabels(ctx, organizationId, newAusLabelKey("version-data."), f.connection)
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("organization %s has no version data inheritance configuration", organizationId)
+	}
+
+	output.Log(dryRun, "Delete version data inheritance configuration from organization %s\n", organizationId)
+	return NewOCMLabelsContainer(labels).Reconcile(ctx, dryRun, f.connection)
+}
+
+func listVersionDataInheritanceConfiguration(ctx context.Context, organizationId string, connection *sdk.Connection) (versiondata.VersionDataInheritanceConfig, error) {
+	labels, err := listOrganizationLabels(ctx, organizationId, newAusLabelKey("version-data."), connection)
 	if err != nil {
 		return versiondata.VersionDataInheritanceConfig{}, err
 	}
 	inheritOrgIds := []string{}
 	publishOrgIds := []string{}
+	var inheritWorkloads, publishWorkloads map[string][]string
 	for _, versionDataLabel := range labels {
-		if versionDataLabel.Key() == INHERIT_LABEL_KEY {
+		key := versionDataLabel.Key()
+		switch {
+		case key == INHERIT_LABEL_KEY:
 			inheritOrgIds = strings.Split(versionDataLabel.Value(), ",")
-		}
-		if versionDataLabel.Key() == PUBLISH_LABEL_KEY {
+		case key == PUBLISH_LABEL_KEY:
 			publishOrgIds = strings.Split(versionDataLabel.Value(), ",")
+		case strings.HasPrefix(key, INHERIT_WORKLOADS_LABEL_PREFIX):
+			if inheritWorkloads == nil {
+				inheritWorkloads = map[string][]string{}
+			}
+			inheritWorkloads[strings.TrimPrefix(key, INHERIT_WORKLOADS_LABEL_PREFIX)] = strings.Split(versionDataLabel.Value(), ",")
+		case strings.HasPrefix(key, PUBLISH_WORKLOADS_LABEL_PREFIX):
+			if publishWorkloads == nil {
+				publishWorkloads = map[string][]string{}
+			}
+			publishWorkloads[strings.TrimPrefix(key, PUBLISH_WORKLOADS_LABEL_PREFIX)] = strings.Split(versionDataLabel.Value(), ",")
 		}
 	}
 	return versiondata.VersionDataInheritanceConfig{
 		InheritingFromOrgs: inheritOrgIds,
 		PublishingToOrgs:   publishOrgIds,
+		InheritWorkloads:   inheritWorkloads,
+		PublishWorkloads:   publishWorkloads,
 	}, nil
 }
@@ -18,16 +18,21 @@ package ocmlabels
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/app-sre/aus-cli/pkg/arguments"
 	"github.com/app-sre/aus-cli/pkg/debug"
+	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/app-sre/aus-cli/pkg/utils"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
 )
 
 type OCMLabelsContainer struct {
@@ -81,7 +86,15 @@ func (lc *OCMLabelsContainer) AddLabels(label []*amv1.Label) bool {
 	return true
 }
 
-func (lc *OCMLabelsContainer) Reconcile(dryRun bool, connection *sdk.Connection) error {
+// Reconcile applies the desired labels and removes obsolete ones. If any of the changes fails, the
+// changes done so far are rolled back and a RollbackError is returned.
+func (lc *OCMLabelsContainer) Reconcile(ctx context.Context, dryRun bool, connection *sdk.Connection) error {
+	transaction := NewOCMLabelsTransaction()
+	transaction.Add(lc)
+	return transaction.Commit(ctx, dryRun, connection)
+}
+
+func (lc *OCMLabelsContainer) reconcile(ctx context.Context, dryRun bool, connection *sdk.Connection, journal *labelsJournal, limiter *ocm.RateLimiter) error {
 	currentLabelsCopy := make(map[string]*amv1.Label)
 	for k, v := range lc.currentLabels {
 		currentLabelsCopy[k] = v
@@ -90,30 +103,40 @@ func (lc *OCMLabelsContainer) Reconcile(dryRun bool, connection *sdk.Connection)
 	// apply labels
 	for _, label := range lc.desiredLabels {
 		delete(currentLabelsCopy, label.Key())
-		err := applyOCMLabel(label, dryRun, connection)
+		err := limiter.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		applied, err := applyOCMLabel(ctx, label, dryRun, connection)
 		if err != nil {
 			return err
 		}
+		journal.record(lc.currentLabels[label.Key()], applied)
 	}
 
 	// remove obsolete labels
 	for _, label := range currentLabelsCopy {
-		err := deleteOCMLabel(label, dryRun, connection)
+		err := limiter.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		err = deleteOCMLabel(ctx, label, dryRun, connection)
 		// maybe ignore 404
 		if err != nil {
 			return err
 		}
+		journal.record(label, nil)
 	}
 
 	return nil
 }
 
-func listOrganizationLabels(organizationId string, keyPrefix string, connection *sdk.Connection) ([]*amv1.Label, error) {
-	org_labels, err := connection.AccountsMgmt().V1().Organizations().Organization(organizationId).Labels().List().Parameter("search", fmt.Sprintf("key like '%s%%'", keyPrefix)).Send()
+func listOrganizationLabels(ctx context.Context, organizationId string, keyPrefix string, connection *sdk.Connection) ([]*amv1.Label, error) {
+	org_labels, err := connection.AccountsMgmt().V1().Organizations().Organization(organizationId).Labels().List().Parameter("search", fmt.Sprintf("key like '%s%%'", keyPrefix)).SendContext(ctx)
 	if err == nil {
 		return org_labels.Items().Slice(), nil
 	}
-	labels, err := connection.AccountsMgmt().V1().Labels().List().Parameter("search", fmt.Sprintf("organization_id = '%s' and key like '%s%%'", organizationId, keyPrefix)).Send()
+	labels, err := connection.AccountsMgmt().V1().Labels().List().Parameter("search", fmt.Sprintf("organization_id = '%s' and key like '%s%%'", organizationId, keyPrefix)).SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -121,8 +144,8 @@ func listOrganizationLabels(organizationId string, keyPrefix string, connection
 
 }
 
-func getOrganizationLabel(organizationId string, key string, connection *sdk.Connection) (*amv1.Label, error) {
-	labels, err := listOrganizationLabels(organizationId, key, connection)
+func getOrganizationLabel(ctx context.Context, organizationId string, key string, connection *sdk.Connection) (*amv1.Label, error) {
+	labels, err := listOrganizationLabels(ctx, organizationId, key, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -135,21 +158,31 @@ func getOrganizationLabel(organizationId string, key string, connection *sdk.Con
 	return labels[0], nil
 }
 
-func listSubscriptionLabels(subscriptionId string, keyPrefix string, connection *sdk.Connection) ([]*amv1.Label, error) {
-	labels, err := connection.AccountsMgmt().V1().Subscriptions().Subscription(subscriptionId).Labels().List().Parameter("search", fmt.Sprintf("key like '%s%%'", keyPrefix)).Send()
+func listSubscriptionLabels(ctx context.Context, subscriptionId string, keyPrefix string, connection *sdk.Connection) ([]*amv1.Label, error) {
+	labels, err := connection.AccountsMgmt().V1().Subscriptions().Subscription(subscriptionId).Labels().List().Parameter("search", fmt.Sprintf("key like '%s%%'", keyPrefix)).SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return labels.Items().Slice(), nil
 }
 
-func deleteSubscriptionLabels(subscriptionId string, keyPrefix string, connection *sdk.Connection, dryRun bool) error {
-	labels, err := listSubscriptionLabels(subscriptionId, keyPrefix, connection)
+func filterLabels(labels []*amv1.Label, keyPrefix string) []*amv1.Label {
+	filtered := []*amv1.Label{}
+	for _, label := range labels {
+		if strings.HasPrefix(label.Key(), keyPrefix) {
+			filtered = append(filtered, label)
+		}
+	}
+	return filtered
+}
+
+func deleteSubscriptionLabels(ctx context.Context, subscriptionId string, keyPrefix string, connection *sdk.Connection, dryRun bool) error {
+	labels, err := listSubscriptionLabels(ctx, subscriptionId, keyPrefix, connection)
 	if err != nil {
 		return err
 	}
 	for _, label := range labels {
-		err = deleteOCMLabel(label, dryRun, connection)
+		err = deleteOCMLabel(ctx, label, dryRun, connection)
 		if err != nil {
 			return err
 		}
@@ -178,7 +211,7 @@ func buildOCMLabel(key string, value string, subscriptionId string, organization
 	return label.Build()
 }
 
-func applyOCMLabel(label *amv1.Label, dryRun bool, connection *sdk.Connection) error {
+func applyOCMLabel(ctx context.Context, label *amv1.Label, dryRun bool, connection *sdk.Connection) (*amv1.Label, error) {
 	var request *amv1.GenericLabelsAddRequest
 	if label.SubscriptionID() != "" {
 		request = connection.AccountsMgmt().V1().Subscriptions().Subscription(label.SubscriptionID()).Labels().Add().Body(label)
@@ -187,36 +220,61 @@ func applyOCMLabel(label *amv1.Label, dryRun bool, connection *sdk.Connection) e
 		request = connection.AccountsMgmt().V1().Organizations().Organization(label.OrganizationID()).Labels().Add().Body(label)
 		output.Debug(dryRun, "Add label %s to organization %s\n", label.Key(), label.OrganizationID())
 	} else {
-		return fmt.Errorf("label is missing subscription_id or organization_id")
+		return nil, fmt.Errorf("label is missing subscription_id or organization_id")
 	}
 	if debug.Enabled() {
 		buf := new(bytes.Buffer)
 		err := amv1.MarshalLabel(label, buf)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		err = dump.Pretty(os.Stdout, buf.Bytes())
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if !dryRun {
-		_, err := request.Send()
-		return err
+		response, err := request.SendContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return response.Body(), nil
 	}
-	return nil
+	return label, nil
 }
 
-func deleteOCMLabel(label *amv1.Label, dryRun bool, connection *sdk.Connection) error {
+func deleteOCMLabel(ctx context.Context, label *amv1.Label, dryRun bool, connection *sdk.Connection) error {
+	href := label.HREF()
+	if href == "" {
+		href = labelHREF(label)
+	}
 	request := connection.Delete()
-	err := arguments.ApplyPathArg(request, label.HREF())
+	err := arguments.ApplyPathArg(request, href)
 	if err != nil {
 		return err
 	}
-	output.Debug(dryRun, "Delete label %s\n", label.HREF())
+	output.Debug(dryRun, "Delete label %s\n", href)
 	if !dryRun {
-		_, err := request.Send()
-		return err
+		response, err := request.SendContext(ctx)
+		if err != nil {
+			return err
+		}
+		// generic requests don't turn error responses into errors, a label that is gone already
+		// counts as deleted
+		if response.Status() >= 400 && response.Status() != http.StatusNotFound {
+			ocmErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+			if err != nil {
+				return fmt.Errorf("failed to delete label %s: status %d", href, response.Status())
+			}
+			return ocmErr
+		}
 	}
 	return nil
 }
+
+func labelHREF(label *amv1.Label) string {
+	if label.SubscriptionID() != "" {
+		return fmt.Sprintf("/api/accounts_mgmt/v1/subscriptions/%s/labels/%s", label.SubscriptionID(), label.Key())
+	}
+	return fmt.Sprintf("/api/accounts_mgmt/v1/organizations/%s/labels/%s", label.OrganizationID(), label.Key())
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/app-sre/aus-cli/pkg/clusters"
 	"github.com/app-sre/aus-cli/pkg/ocm"
@@ -38,6 +42,23 @@ var SECTOR_LABEL_KEY = newAusLabelKey("sector")
 var SCHEDULE_LABEL_KEY = newAusLabelKey("schedule")
 var MUTEXES_LABEL_KEY = newAusLabelKey("mutexes")
 var BLOCKED_VERSIONS_LABEL_KEY = newAusLabelKey("blocked-versions")
+var MAX_VERSION_LABEL_KEY = newAusLabelKey("max-version")
+var Z_STREAM_ONLY_LABEL_KEY = newAusLabelKey("z-stream-only")
+var MIN_UPGRADE_INTERVAL_DAYS_LABEL_KEY = newAusLabelKey("min-upgrade-interval-days")
+var REQUIRE_APPROVAL_LABEL_KEY = newAusLabelKey("require-approval")
+var CANARY_LABEL_KEY = newAusLabelKey("canary")
+
+var OWNER_LABEL_KEY = newAusLabelKey("owner")
+var CONTACT_LABEL_KEY = newAusLabelKey("contact")
+var TICKET_LABEL_KEY = newAusLabelKey("ticket")
+
+// OWNERSHIP_LABELS are reconciled separately from the policy labels, so applying a policy
+// without ownership keeps the ownership of the cluster
+var OWNERSHIP_LABELS = []string{
+	OWNER_LABEL_KEY,
+	CONTACT_LABEL_KEY,
+	TICKET_LABEL_KEY,
+}
 
 var SUPPORTED_POLICY_LABELS = []string{
 	SOAK_DAYS_LABEL_KEY,
@@ -46,112 +67,453 @@ var SUPPORTED_POLICY_LABELS = []string{
 	SCHEDULE_LABEL_KEY,
 	MUTEXES_LABEL_KEY,
 	BLOCKED_VERSIONS_LABEL_KEY,
+	MAX_VERSION_LABEL_KEY,
+	Z_STREAM_ONLY_LABEL_KEY,
+	MIN_UPGRADE_INTERVAL_DAYS_LABEL_KEY,
+	REQUIRE_APPROVAL_LABEL_KEY,
+	CANARY_LABEL_KEY,
 }
 
-func (f *OCMLabelsPolicyBackend) ListPolicies(organizationId string, showClustersWithoutPolicy bool) (map[string]*clusters.ClusterInfo, error) {
-	connection, err := ocm.NewOCMConnection()
+func (f *OCMLabelsPolicyBackend) ListPolicies(ctx context.Context, organizationId string, showClustersWithoutPolicy bool) (map[string]*clusters.ClusterInfo, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return nil, err
 	}
 
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return listPoliciesInOrganization(organizationId, showClustersWithoutPolicy, connection)
+	return listPoliciesInOrganization(ctx, organizationId, showClustersWithoutPolicy, f.connection)
 }
 
-func (f *OCMLabelsPolicyBackend) DeletePolicy(organizationId string, clusterName string, dryRun bool) error {
-	connection, err := ocm.NewOCMConnection()
+func (f *OCMLabelsPolicyBackend) DeletePolicy(ctx context.Context, organizationId string, clusterName string, guard policy.OwnerGuard, dryRun bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return err
 	}
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return err
-		}
-	}
 
-	subscription, err := ocm.SubscriptionForDisplayName(organizationId, clusterName, connection)
+	subscription, err := ocm.SubscriptionForDisplayName(ctx, organizationId, clusterName, f.connection)
 	if err != nil {
 		return err
 	}
+	if err := guard.Check(clusterName, subscriptionOwner(subscription)); err != nil {
+		return err
+	}
 
 	output.Log(dryRun, "Delete cluster upgrade policy from %s\n", clusterName)
-	return deleteSubscriptionLabels(subscription.ID(), newAusLabelKey(""), connection, dryRun)
+	return deleteSubscriptionLabels(ctx, subscription.ID(), newAusLabelKey(""), f.connection, dryRun)
 }
 
-func (f *OCMLabelsPolicyBackend) ApplyPolicies(organizationId string, policies []policy.ClusterUpgradePolicy, dumpPolicy bool, dryRun bool) error {
-	if dumpPolicy {
+func (f *OCMLabelsPolicyBackend) DeletePolicies(ctx context.Context, organizationId string, clusterNames []string, guard policy.OwnerGuard, dryRun bool) ([]policy.ApplyResult, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := ocm.NewSubscriptionIndex(ctx, organizationId, f.connection)
+	if err != nil {
+		return nil, err
+	}
+	// resolve all clusters first so that a typo doesn't leave a partially deleted set behind
+	for _, clusterName := range clusterNames {
+		if _, err := subscriptions.ForDisplayName(clusterName); err != nil {
+			return nil, err
+		}
+	}
+	refused := checkOwners(clusterNames, subscriptions, guard)
+
+	results := make([]policy.ApplyResult, 0, len(clusterNames))
+	for i, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			results = append(results, newApplyResult(clusterName, ctx.Err()))
+			continue
+		}
+		err, ok := refused[clusterName]
+		if !ok {
+			err = deletePolicy(ctx, clusterName, subscriptions, f.connection, dryRun)
+		}
+		results = append(results, newApplyResult(clusterName, err))
+		if err != nil {
+			output.Log(dryRun, "[%d/%d] Failed to delete cluster upgrade policy from %s: %v\n", i+1, len(clusterNames), clusterName, err)
+		} else {
+			output.Log(dryRun, "[%d/%d] Deleted cluster upgrade policy from %s\n", i+1, len(clusterNames), clusterName)
+		}
+	}
+	failed := policy.FailedApplyResults(results)
+	output.Log(dryRun, "Deleted %d of %d cluster upgrade policies\n", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		return results, fmt.Errorf("failed to delete %d of %d cluster upgrade policies", len(failed), len(results))
+	}
+	return results, nil
+}
+
+func (f *OCMLabelsPolicyBackend) ApplyPolicies(ctx context.Context, organizationId string, policies []policy.ClusterUpgradePolicy, options policy.ApplyOptions) ([]policy.ApplyResult, error) {
+	if options.Dump {
 		body, err := json.Marshal(policies)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		err = output.PrettyList(os.Stdout, body)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return nil
+		return nil, nil
 	}
 
-	connection, err := ocm.NewOCMConnection()
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
+
+	policies, err = expandSelectorPolicies(ctx, organizationId, policies, f.connection, options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := getPolicyDefaults(ctx, organizationId, f.connection)
+	if err != nil {
+		return nil, err
+	}
+
+	// prefetch all subscriptions with their labels once
+	subscriptions, err := ocm.NewSubscriptionIndex(ctx, organizationId, f.connection)
+	if err != nil {
+		return nil, err
+	}
+
+	// an invalid policy only fails its own cluster, unless all policies are applied as a unit
+	policies, invalid := withPolicyDefaults(policies, defaults)
+	clusterNames := make([]string, 0, len(policies))
+	for _, p := range policies {
+		clusterNames = append(clusterNames, p.ClusterName)
+	}
+	for clusterName, err := range checkOwners(clusterNames, subscriptions, options.OwnerGuard) {
+		invalid[clusterName] = errors.Join(invalid[clusterName], err)
+	}
+	for clusterName, err := range validateCanaries(policies, invalid, subscriptions) {
+		invalid[clusterName] = errors.Join(invalid[clusterName], err)
+	}
+	results := make([]policy.ApplyResult, len(policies))
+	valid := []policy.ClusterUpgradePolicy{}
+	validIndexes := []int{}
+	for i, p := range policies {
+		if err, ok := invalid[p.ClusterName]; ok {
+			results[i] = newApplyResult(p.ClusterName, err)
+			output.Log(options.DryRun, "Invalid cluster upgrade policy for %s: %v\n", p.ClusterName, err)
+		} else {
+			valid = append(valid, p)
+			validIndexes = append(validIndexes, i)
+		}
+	}
+	limiter := ocm.NewRateLimiter(options.RequestsPerSecond)
+
+	if options.Atomic {
+		if len(invalid) > 0 {
+			for _, i := range validIndexes {
+				results[i] = newApplyResult(policies[i].ClusterName, errAtomicApplyAborted)
+			}
+			return results, fmt.Errorf("%d of %d cluster upgrade policies are invalid, none were applied", len(invalid), len(policies))
+		}
+		return applyPoliciesAtomically(ctx, policies, subscriptions, limiter, f.connection, options.DryRun)
+	}
+	for i, result := range applyPoliciesConcurrently(ctx, valid, subscriptions, limiter, f.connection, options) {
+		results[validIndexes[i]] = result
+	}
+	failed := policy.FailedApplyResults(results)
+	output.Log(options.DryRun, "Applied %d of %d cluster upgrade policies\n", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		return results, fmt.Errorf("failed to apply %d of %d cluster upgrade policies", len(failed), len(results))
+	}
+	return results, nil
+}
+
+// errAtomicApplyAborted is the result of valid policies that weren't applied atomically because
+// other policies are invalid.
+var errAtomicApplyAborted = errors.New("not applied, other policies of the atomic apply are invalid")
+
+// withPolicyDefaults prepares policies for being stored on the clusters. Values inherited from the
+// organization defaults are not stored and every policy needs to be complete once merged with the
+// defaults. Invalid policies are returned unchanged together with their errors by cluster name.
+func withPolicyDefaults(policies []policy.ClusterUpgradePolicy, defaults policy.PolicyDefaults) ([]policy.ClusterUpgradePolicy, map[string]error) {
+	prepared := make([]policy.ClusterUpgradePolicy, 0, len(policies))
+	invalid := map[string]error{}
+	for _, p := range policies {
+		explicit, err := defaults.Explicit(p)
+		if err == nil {
+			err = defaults.Merge(explicit).Validate()
+		}
+		if err != nil {
+			invalid[p.ClusterName] = fmt.Errorf("invalid policy for cluster %s: %v", p.ClusterName, err)
+			prepared = append(prepared, p)
+			continue
+		}
+		// without a default, missing soak days are stored as 0 like before defaults existed
+		if explicit.Conditions.SoakDays == nil && defaults.SoakDays == nil {
+			soakDays := 0
+			explicit.Conditions.SoakDays = &soakDays
+		}
+		prepared = append(prepared, explicit)
+	}
+	return prepared, invalid
+}
+
+// expandSelectorPolicies replaces every policy with a selector by a copy of the policy for each
+// matching cluster. Policies for explicitly named clusters take precedence over selectors.
+func expandSelectorPolicies(ctx context.Context, organizationId string, policies []policy.ClusterUpgradePolicy, connection *sdk.Connection, dryRun bool) ([]policy.ClusterUpgradePolicy, error) {
+	expanded := []policy.ClusterUpgradePolicy{}
+	named := make(map[string]bool)
+	for _, p := range policies {
+		if len(p.Selector) == 0 {
+			expanded = append(expanded, p)
+			named[p.ClusterName] = true
+		}
+	}
+
+	selectedBy := make(map[string]string)
+	for _, p := range policies {
+		if len(p.Selector) == 0 {
+			continue
+		}
+		selector := clusters.NewSelector(p.Selector)
+		if err := selector.Validate(); err != nil {
+			return nil, err
+		}
+		clusterInfos, err := clusters.ClusterInfosForOrganization(ctx, organizationId, selector.SubscriptionSearchQuery(), false, connection)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		matches := clusters.SelectClusters(clusterInfos, selector)
+		output.Log(dryRun, "Selector %s matches %d cluster(s)\n", selector, len(matches))
+		for _, clusterInfo := range matches {
+			clusterName := clusterInfo.Subscription.DisplayName()
+			if named[clusterName] {
+				continue
+			}
+			if other, ok := selectedBy[clusterName]; ok {
+				return nil, fmt.Errorf("cluster %s is matched by selector %s and selector %s", clusterName, other, selector)
+			}
+			selectedBy[clusterName] = selector.String()
+			clusterPolicy := p
+			clusterPolicy.ClusterName = clusterName
+			clusterPolicy.Selector = nil
+			expanded = append(expanded, clusterPolicy)
 		}
 	}
+	return expanded, nil
+}
 
+func applyPoliciesAtomically(ctx context.Context, policies []policy.ClusterUpgradePolicy, subscriptions *ocm.SubscriptionIndex, limiter *ocm.RateLimiter, connection *sdk.Connection, dryRun bool) ([]policy.ApplyResult, error) {
+	// prepare all policies first and reconcile them as a unit
+	transaction := NewOCMLabelsTransaction()
+	transaction.RateLimit(limiter)
 	for _, policy := range policies {
-		err := f.applyPolicy(organizationId, policy, connection, dryRun)
+		labelsContainers, err := policyLabelsContainers(policy, subscriptions)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for _, labelsContainer := range labelsContainers {
+			transaction.Add(labelsContainer)
 		}
 	}
-	return nil
+	output.Log(dryRun, "Apply %d cluster upgrade policies atomically\n", len(policies))
+	err := transaction.Commit(ctx, dryRun, connection)
+	results := make([]policy.ApplyResult, 0, len(policies))
+	for _, policy := range policies {
+		results = append(results, newApplyResult(policy.ClusterName, err))
+	}
+	return results, err
 }
 
-func (f *OCMLabelsPolicyBackend) applyPolicy(organizationId string, policy policy.ClusterUpgradePolicy, connection *sdk.Connection, dryRun bool) error {
-	subscription, err := ocm.SubscriptionForDisplayName(organizationId, policy.ClusterName, connection)
+func applyPoliciesConcurrently(ctx context.Context, policies []policy.ClusterUpgradePolicy, subscriptions *ocm.SubscriptionIndex, limiter *ocm.RateLimiter, connection *sdk.Connection, options policy.ApplyOptions) []policy.ApplyResult {
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]policy.ApplyResult, len(policies))
+	indexes := make(chan int)
+	var progressMutex sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				err := applyPolicy(ctx, policies[i], subscriptions, limiter, connection, options.DryRun)
+				results[i] = newApplyResult(policies[i].ClusterName, err)
+
+				progressMutex.Lock()
+				done++
+				if err != nil {
+					output.Log(options.DryRun, "[%d/%d] Failed to apply cluster upgrade policy to %s: %v\n", done, len(policies), policies[i].ClusterName, err)
+				} else {
+					output.Log(options.DryRun, "[%d/%d] Applied cluster upgrade policy to %s\n", done, len(policies), policies[i].ClusterName)
+				}
+				progressMutex.Unlock()
+			}
+		}()
+	}
+dispatch:
+	for i := range policies {
+		select {
+		case <-ctx.Done():
+			// don't start any more policies once cancelled
+			for j := i; j < len(policies); j++ {
+				results[j] = newApplyResult(policies[j].ClusterName, ctx.Err())
+			}
+			break dispatch
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func applyPolicy(ctx context.Context, policy policy.ClusterUpgradePolicy, subscriptions *ocm.SubscriptionIndex, limiter *ocm.RateLimiter, connection *sdk.Connection, dryRun bool) error {
+	labelsContainers, err := policyLabelsContainers(policy, subscriptions)
 	if err != nil {
 		return err
 	}
+	transaction := NewOCMLabelsTransaction()
+	transaction.RateLimit(limiter)
+	for _, labelsContainer := range labelsContainers {
+		transaction.Add(labelsContainer)
+	}
+	return transaction.Commit(ctx, dryRun, connection)
+}
 
-	// get current labels and build a container out of them
-	policyLabels, err := listSubscriptionLabels(subscription.ID(), newAusLabelKey(""), connection)
+func deletePolicy(ctx context.Context, clusterName string, subscriptions *ocm.SubscriptionIndex, connection *sdk.Connection, dryRun bool) error {
+	subscription, err := subscriptions.ForDisplayName(clusterName)
 	if err != nil {
 		return err
 	}
+	// an empty container removes all current labels, a failed delete restores the deleted ones
+	transaction := NewOCMLabelsTransaction()
+	transaction.Add(NewOCMLabelsContainer(filterLabels(subscription.Labels(), newAusLabelKey(""))))
+	return transaction.Commit(ctx, dryRun, connection)
+}
+
+func policyLabelsContainers(policy policy.ClusterUpgradePolicy, subscriptions *ocm.SubscriptionIndex) ([]*OCMLabelsContainer, error) {
+	subscription, err := subscriptions.ForDisplayName(policy.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	// build a container out of the current labels
+	policyLabels := filterLabels(subscription.Labels(), newAusLabelKey(""))
 	labelsContainer := NewRestrictingOCMLabelsContainer(policyLabels, SUPPORTED_POLICY_LABELS)
 
 	// build labels for policy and add them to the container
 	desiredLabels, err := newClusterUpgradePolicyFromOCMLabels(policy, subscription.ID())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	labelsContainer.AddLabels(desiredLabels)
+	if !policy.HasOwnership() {
+		return []*OCMLabelsContainer{labelsContainer}, nil
+	}
+
+	ownershipContainer := NewRestrictingOCMLabelsContainer(policyLabels, OWNERSHIP_LABELS)
+	ownershipContainer.AddLabels(newOwnershipLabels(policy, subscription.ID()))
+	return []*OCMLabelsContainer{labelsContainer, ownershipContainer}, nil
+}
+
+// validateCanaries checks the canaries of the organization once the valid policies are applied.
+// The conflicts are returned for the applied canaries that cause them, by cluster name.
+func validateCanaries(policies []policy.ClusterUpgradePolicy, invalid map[string]error, subscriptions *ocm.SubscriptionIndex) map[string]error {
+	byClusterName := map[string]policy.ClusterUpgradePolicy{}
+	applied := map[string]bool{}
+	for _, p := range policies {
+		if _, ok := invalid[p.ClusterName]; !ok {
+			byClusterName[p.ClusterName] = p
+			applied[p.ClusterName] = true
+		}
+	}
+	previous := []policy.ClusterUpgradePolicy{}
+	for _, subscription := range subscriptions.Subscriptions() {
+		// only the current canaries can conflict with the applied policies
+		if _, ok := newLabelMap(subscription.Labels(), SUPPORTED_POLICY_LABELS)[CANARY_LABEL_KEY]; !ok {
+			continue
+		}
+		// canaries and workloads are never inherited, so the defaults don't matter here
+		current, err := getPolicyForSubscription(subscription, nil, policy.PolicyDefaults{})
+		if err != nil {
+			warn("ignoring the invalid policy of cluster %s: %v", subscription.DisplayName(), err)
+			continue
+		}
+		previous = append(previous, *current)
+		if _, ok := byClusterName[subscription.DisplayName()]; !ok {
+			byClusterName[subscription.DisplayName()] = *current
+		}
+	}
+	all := make([]policy.ClusterUpgradePolicy, 0, len(byClusterName))
+	for _, p := range byClusterName {
+		all = append(all, p)
+	}
+	conflicts := map[string]error{}
+	for _, conflict := range policy.CanaryConflicts(all, previous) {
+		// blame the applied policies that add a canary or remove the last one
+		for _, clusterName := range append(conflict.Canaries, conflict.Previous...) {
+			if applied[clusterName] {
+				conflicts[clusterName] = errors.Join(conflicts[clusterName], conflict)
+			}
+		}
+	}
+	return conflicts
+}
+
+func subscriptionOwner(subscription *amv1.Subscription) string {
+	if label, ok := newLabelMap(subscription.Labels(), OWNERSHIP_LABELS)[OWNER_LABEL_KEY]; ok {
+		return label.Value()
+	}
+	return ""
+}
+
+// checkOwners returns the refusals of the guard for clusters owned by other teams, by cluster name.
+func checkOwners(clusterNames []string, subscriptions *ocm.SubscriptionIndex, guard policy.OwnerGuard) map[string]error {
+	refused := map[string]error{}
+	for _, clusterName := range clusterNames {
+		subscription, err := subscriptions.ForDisplayName(clusterName)
+		if err != nil {
+			// unknown clusters are reported when the policy is applied
+			continue
+		}
+		if err := guard.Check(clusterName, subscriptionOwner(subscription)); err != nil {
+			refused[clusterName] = err
+		}
+	}
+	return refused
+}
+
+func newOwnershipLabels(policy policy.ClusterUpgradePolicy, subscriptionID string) []*amv1.Label {
+	labels := []*amv1.Label{}
+	for key, value := range map[string]string{
+		OWNER_LABEL_KEY:   policy.Owner,
+		CONTACT_LABEL_KEY: policy.Contact,
+		TICKET_LABEL_KEY:  policy.Ticket,
+	} {
+		if value != "" {
+			label, _ := buildOCMLabel(key, value, subscriptionID, "")
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
 
-	// reconcile
-	output.Log(dryRun, "Apply cluster upgrade policy to %s\n", policy.ClusterName)
-	return labelsContainer.Reconcile(dryRun, connection)
+func newApplyResult(clusterName string, err error) policy.ApplyResult {
+	return policy.ApplyResult{
+		ClusterName: clusterName,
+		Err:         err,
+	}
 }
 
-func listPoliciesInOrganization(organizationId string, showClustersWithoutPolicy bool, connection *sdk.Connection) (map[string]*clusters.ClusterInfo, error) {
+func listPoliciesInOrganization(ctx context.Context, organizationId string, showClustersWithoutPolicy bool, connection *sdk.Connection) (map[string]*clusters.ClusterInfo, error) {
 	cluster_map := make(map[string]*clusters.ClusterInfo)
-	clusterInfos, err := getClusterInfos(organizationId, "", connection)
+	clusterInfos, err := getClusterInfos(ctx, organizationId, "", connection)
 	if err != nil {
 		return nil, err
 	}
 	for _, cluster := range clusterInfos {
 		if (cluster.Policy != nil && cluster.Policy.Validate() == nil) || showClustersWithoutPolicy {
-			cluster_map[cluster.Cluster.Name()] = cluster
+			cluster_map[cluster.DisplayName()] = cluster
 		}
 	}
 	return cluster_map, nil
@@ -159,16 +521,20 @@ func listPoliciesInOrganization(organizationId string, showClustersWithoutPolicy
 
 func newClusterUpgradePolicyFromOCMLabels(policy policy.ClusterUpgradePolicy, subscriptionID string) ([]*amv1.Label, error) {
 	labels := []*amv1.Label{}
-	soakDayLabel, _ := buildOCMLabel(SOAK_DAYS_LABEL_KEY, strconv.Itoa(policy.Conditions.SoakDays), subscriptionID, "")
-	labels = append(labels, soakDayLabel)
+	if policy.Conditions.SoakDays != nil {
+		soakDayLabel, _ := buildOCMLabel(SOAK_DAYS_LABEL_KEY, strconv.Itoa(*policy.Conditions.SoakDays), subscriptionID, "")
+		labels = append(labels, soakDayLabel)
+	}
 	workloadsLabel, _ := buildOCMLabel(WORKLOADS_LABEL_KEY, utils.StringArrayToCSV(policy.Workloads), subscriptionID, "")
 	labels = append(labels, workloadsLabel)
 	if policy.Conditions.Sector != "" {
 		sectorLabel, _ := buildOCMLabel(SECTOR_LABEL_KEY, policy.Conditions.Sector, subscriptionID, "")
 		labels = append(labels, sectorLabel)
 	}
-	scheduleLabel, _ := buildOCMLabel(SCHEDULE_LABEL_KEY, policy.Schedule, subscriptionID, "")
-	labels = append(labels, scheduleLabel)
+	if policy.Schedule != "" {
+		scheduleLabel, _ := buildOCMLabel(SCHEDULE_LABEL_KEY, policy.Schedule, subscriptionID, "")
+		labels = append(labels, scheduleLabel)
+	}
 	if len(policy.Conditions.Mutexes) > 0 {
 		mutexesLabel, _ := buildOCMLabel(MUTEXES_LABEL_KEY, utils.StringArrayToCSV(policy.Conditions.Mutexes), subscriptionID, "")
 		labels = append(labels, mutexesLabel)
@@ -177,10 +543,32 @@ func newClusterUpgradePolicyFromOCMLabels(policy policy.ClusterUpgradePolicy, su
 		blockedVersionsLabel, _ := buildOCMLabel(BLOCKED_VERSIONS_LABEL_KEY, utils.StringArrayToCSV(policy.Conditions.BlockedVersions), subscriptionID, "")
 		labels = append(labels, blockedVersionsLabel)
 	}
+	if policy.Conditions.MaxVersion != "" {
+		maxVersionLabel, _ := buildOCMLabel(MAX_VERSION_LABEL_KEY, policy.Conditions.MaxVersion, subscriptionID, "")
+		labels = append(labels, maxVersionLabel)
+	}
+	if policy.Conditions.ZStreamOnly {
+		zStreamOnlyLabel, _ := buildOCMLabel(Z_STREAM_ONLY_LABEL_KEY, strconv.FormatBool(policy.Conditions.ZStreamOnly), subscriptionID, "")
+		labels = append(labels, zStreamOnlyLabel)
+	}
+	if policy.Conditions.MinUpgradeIntervalDays > 0 {
+		minUpgradeIntervalDaysLabel, _ := buildOCMLabel(MIN_UPGRADE_INTERVAL_DAYS_LABEL_KEY, strconv.Itoa(policy.Conditions.MinUpgradeIntervalDays), subscriptionID, "")
+		labels = append(labels, minUpgradeIntervalDaysLabel)
+	}
+	if policy.Conditions.RequireApproval {
+		requireApprovalLabel, _ := buildOCMLabel(REQUIRE_APPROVAL_LABEL_KEY, strconv.FormatBool(policy.Conditions.RequireApproval), subscriptionID, "")
+		labels = append(labels, requireApprovalLabel)
+	}
+	if policy.Conditions.Canary {
+		canaryLabel, _ := buildOCMLabel(CANARY_LABEL_KEY, strconv.FormatBool(policy.Conditions.Canary), subscriptionID, "")
+		labels = append(labels, canaryLabel)
+	}
 	return labels, nil
 }
 
-func getPolicyForSubscription(subscription *amv1.Subscription, cluster *csv1.Cluster) (*policy.ClusterUpgradePolicy, error) {
+// getPolicyForSubscription reads the policy from the subscription labels. Values the cluster
+// doesn't set are inherited from the organization defaults.
+func getPolicyForSubscription(subscription *amv1.Subscription, cluster *csv1.Cluster, defaults policy.PolicyDefaults) (*policy.ClusterUpgradePolicy, error) {
 	labelsMap := newLabelMap(subscription.Labels(), SUPPORTED_POLICY_LABELS)
 	policy := policy.ClusterUpgradePolicy{
 		ClusterName: subscription.DisplayName(),
@@ -208,7 +596,7 @@ func getPolicyForSubscription(subscription *amv1.Subscription, cluster *csv1.Clu
 		if err != nil {
 			return nil, err
 		}
-		policy.Conditions.SoakDays = soakDays
+		policy.Conditions.SoakDays = &soakDays
 	}
 	sectorLabel, ok := labelsMap[SECTOR_LABEL_KEY]
 	if ok {
@@ -220,5 +608,52 @@ func getPolicyForSubscription(subscription *amv1.Subscription, cluster *csv1.Clu
 	} else {
 		policy.Conditions.BlockedVersions = []string{}
 	}
-	return &policy, nil
+	maxVersionLabel, ok := labelsMap[MAX_VERSION_LABEL_KEY]
+	if ok {
+		policy.Conditions.MaxVersion = maxVersionLabel.Value()
+	}
+	zStreamOnlyLabel, ok := labelsMap[Z_STREAM_ONLY_LABEL_KEY]
+	if ok {
+		zStreamOnly, err := strconv.ParseBool(zStreamOnlyLabel.Value())
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions.ZStreamOnly = zStreamOnly
+	}
+	minUpgradeIntervalDaysLabel, ok := labelsMap[MIN_UPGRADE_INTERVAL_DAYS_LABEL_KEY]
+	if ok {
+		minUpgradeIntervalDays, err := strconv.Atoi(minUpgradeIntervalDaysLabel.Value())
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions.MinUpgradeIntervalDays = minUpgradeIntervalDays
+	}
+	requireApprovalLabel, ok := labelsMap[REQUIRE_APPROVAL_LABEL_KEY]
+	if ok {
+		requireApproval, err := strconv.ParseBool(requireApprovalLabel.Value())
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions.RequireApproval = requireApproval
+	}
+	canaryLabel, ok := labelsMap[CANARY_LABEL_KEY]
+	if ok {
+		canary, err := strconv.ParseBool(canaryLabel.Value())
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions.Canary = canary
+	}
+	ownershipLabels := newLabelMap(subscription.Labels(), OWNERSHIP_LABELS)
+	if label, ok := ownershipLabels[OWNER_LABEL_KEY]; ok {
+		policy.Owner = label.Value()
+	}
+	if label, ok := ownershipLabels[CONTACT_LABEL_KEY]; ok {
+		policy.Contact = label.Value()
+	}
+	if label, ok := ownershipLabels[TICKET_LABEL_KEY]; ok {
+		policy.Ticket = label.Value()
+	}
+	merged := defaults.Merge(policy)
+	return &merged, nil
 }
@@ -17,12 +17,13 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
-	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/output"
 	"github.com/app-sre/aus-cli/pkg/sectors"
 	"github.com/app-sre/aus-cli/pkg/utils"
@@ -30,22 +31,15 @@ import (
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
-func (f *OCMLabelsPolicyBackend) ListSectorConfiguration(organizationId string) ([]sectors.Sector, error) {
-	connection, err := ocm.NewOCMConnection()
+func (f *OCMLabelsPolicyBackend) ListSectorConfiguration(ctx context.Context, organizationId string) ([]sectors.Sector, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return nil, err
 	}
-
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return listSectorsFromOrganizationLabels(organizationId, connection)
+	return listSectorsFromOrganizationLabels(ctx, organizationId, f.connection)
 }
 
-func (f *OCMLabelsPolicyBackend) ApplySectorConfiguration(organizationId string, sectors []sectors.Sector, dumpSectors bool, dryRun bool) error {
+func (f *OCMLabelsPolicyBackend) ApplySectorConfiguration(ctx context.Context, organizationId string, sectors []sectors.Sector, dumpSectors bool, dryRun bool) error {
 	if dumpSectors {
 		body, err := json.Marshal(sectors)
 		if err != nil {
@@ -58,21 +52,14 @@ func (f *OCMLabelsPolicyBackend) ApplySectorConfiguration(organizationId string,
 		return nil
 	}
 
-	connection, err := ocm.NewOCMConnection()
+	organizationId, err := f.organizationId(ctx, organizationId)
 	if err != nil {
 		return err
 	}
 
-	if organizationId == "" {
-		organizationId, err = ocm.CurrentOrganizationId(connection)
-		if err != nil {
-			return err
-		}
-	}
-
 	output.Log(dryRun, "Apply sector configuration to organization %s\n", organizationId)
 
-	sectorLabels, err := listOrganizationSectorLabels(organizationId, connection)
+	sectorLabels, err := listOrganizationSectorLabels(ctx, organizationId, f.connection)
 	if err != nil {
 		return err
 	}
@@ -95,23 +82,100 @@ func (f *OCMLabelsPolicyBackend) ApplySectorConfiguration(organizationId string,
 		}
 	}
 
-	return labelsContainer.Reconcile(dryRun, connection)
+	return labelsContainer.Reconcile(ctx, dryRun, f.connection)
+}
+
+func (f *OCMLabelsPolicyBackend) CheckDeleteSector(ctx context.Context, organizationId string, sectorName string, force bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+	_, err = checkDeleteSector(ctx, organizationId, sectorName, force, f.connection)
+	return err
+}
+
+func (f *OCMLabelsPolicyBackend) DeleteSector(ctx context.Context, organizationId string, sectorName string, force bool, dryRun bool) error {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	clusterNames, err := checkDeleteSector(ctx, organizationId, sectorName, force, f.connection)
+	if err != nil {
+		return err
+	}
+	if len(clusterNames) > 0 {
+		warn("sector %s is still used by the policies of clusters %s", sectorName, strings.Join(clusterNames, ", "))
+	}
+
+	output.Log(dryRun, "Delete sector %s from organization %s\n", sectorName, organizationId)
+
+	// the label search matches prefixes, so only the labels of this sector are kept
+	sectorLabels, err := listOrganizationSectorLabels(ctx, organizationId, f.connection)
+	if err != nil {
+		return err
+	}
+	labels := []*amv1.Label{}
+	for _, label := range sectorLabels {
+		if label.Key() == newAusLabelKey(fmt.Sprintf("sector-deps.%s", sectorName)) ||
+			label.Key() == newAusLabelKey(fmt.Sprintf("sector-max-parallel-upgrades.%s", sectorName)) {
+			labels = append(labels, label)
+		}
+	}
+	return NewOCMLabelsContainer(labels).Reconcile(ctx, dryRun, f.connection)
+}
+
+// checkDeleteSector checks that the sector exists and no other sector depends on it. It returns the
+// clusters whose policies still use the sector, which is an error unless the deletion is forced.
+func checkDeleteSector(ctx context.Context, organizationId string, sectorName string, force bool, connection *sdk.Connection) ([]string, error) {
+	currentSectors, err := listSectorsFromOrganizationLabels(ctx, organizationId, connection)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, sector := range currentSectors {
+		if sector.Name == sectorName {
+			found = true
+		} else if utils.StringInArray(sector.Dependencies, sectorName) {
+			return nil, fmt.Errorf("sector %s depends on sector %s, remove the dependency first", sector.Name, sectorName)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("sector %s not found in organization %s", sectorName, organizationId)
+	}
+
+	// policies keep referring to a deleted sector, so only delete used sectors on request
+	clusterInfos, err := getClusterInfos(ctx, organizationId, "", connection)
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := []string{}
+	for _, cluster := range clusterInfos {
+		if cluster.Policy != nil && cluster.Policy.Conditions.Sector == sectorName {
+			clusterNames = append(clusterNames, cluster.DisplayName())
+		}
+	}
+	sort.Strings(clusterNames)
+	if len(clusterNames) > 0 && !force {
+		return nil, fmt.Errorf("sector %s is used by the policies of clusters %s, move them to another sector first or force the deletion", sectorName, strings.Join(clusterNames, ", "))
+	}
+	return clusterNames, nil
 }
 
-func listOrganizationSectorDependenciesLabels(organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
-	return listOrganizationLabels(organizationId, newAusLabelKey("sector-deps."), connection)
+func listOrganizationSectorDependenciesLabels(ctx context.Context, organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
+	return listOrganizationLabels(ctx, organizationId, newAusLabelKey("sector-deps."), connection)
 }
 
-func listOrganizationSectorMaxParallelUpgradesLabels(organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
-	return listOrganizationLabels(organizationId, newAusLabelKey("sector-max-parallel-upgrades."), connection)
+func listOrganizationSectorMaxParallelUpgradesLabels(ctx context.Context, organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
+	return listOrganizationLabels(ctx, organizationId, newAusLabelKey("sector-max-parallel-upgrades."), connection)
 }
 
-func listOrganizationSectorLabels(organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
-	sectorDependenciesLabels, err := listOrganizationSectorDependenciesLabels(organizationId, connection)
+func listOrganizationSectorLabels(ctx context.Context, organizationId string, connection *sdk.Connection) ([]*amv1.Label, error) {
+	sectorDependenciesLabels, err := listOrganizationSectorDependenciesLabels(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
-	sectorMaxParallelUpgradesLabels, err := listOrganizationSectorMaxParallelUpgradesLabels(organizationId, connection)
+	sectorMaxParallelUpgradesLabels, err := listOrganizationSectorMaxParallelUpgradesLabels(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -134,10 +198,10 @@ func addOrUpdateSector(sectorMap map[string]sectors.Sector, sector sectors.Secto
 	}
 }
 
-func listSectorsFromOrganizationLabels(organizationId string, connection *sdk.Connection) ([]sectors.Sector, error) {
+func listSectorsFromOrganizationLabels(ctx context.Context, organizationId string, connection *sdk.Connection) ([]sectors.Sector, error) {
 	sectorMap := make(map[string]sectors.Sector)
 
-	labels, err := listOrganizationSectorDependenciesLabels(organizationId, connection)
+	labels, err := listOrganizationSectorDependenciesLabels(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +211,7 @@ func listSectorsFromOrganizationLabels(organizationId string, connection *sdk.Co
 		addOrUpdateSector(sectorMap, sector)
 	}
 
-	labels, err = listOrganizationSectorMaxParallelUpgradesLabels(organizationId, connection)
+	labels, err = listOrganizationSectorMaxParallelUpgradesLabels(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"github.com/app-sre/aus-cli/pkg/clusters"
 	"github.com/app-sre/aus-cli/pkg/ocm"
 	"github.com/app-sre/aus-cli/pkg/sectors"
@@ -24,21 +25,17 @@ import (
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
-func (f *OCMLabelsPolicyBackend) Status(organizationId string, showClustersWithoutPolicy bool) (organization *amv1.Organization, clusterInfos []*clusters.ClusterInfo, blockedVersions []string, sectors []sectors.Sector, inheritance versiondata.VersionDataInheritanceConfig, err error) {
-	connection, err := ocm.NewOCMConnection()
-	if err != nil {
-		return
-	}
-	organization, err = ocm.GetOrganization(organizationId, connection)
+func (f *OCMLabelsPolicyBackend) Status(ctx context.Context, organizationId string, showClustersWithoutPolicy bool) (organization *amv1.Organization, clusterInfos []*clusters.ClusterInfo, blockedVersions []string, sectors []sectors.Sector, inheritance versiondata.VersionDataInheritanceConfig, err error) {
+	organization, err = ocm.GetOrganization(ctx, organizationId, f.connection)
 	if err != nil {
 		return
 	}
 
-	blockedVersions, err = getBlockedVersionsForOrganization(organization.ID(), connection)
+	blockedVersions, err = getBlockedVersionsForOrganization(ctx, organization.ID(), f.connection)
 	if err != nil {
 		return
 	}
-	clustersMap, err := listPoliciesInOrganization(organization.ID(), showClustersWithoutPolicy, connection)
+	clustersMap, err := listPoliciesInOrganization(ctx, organization.ID(), showClustersWithoutPolicy, f.connection)
 	if err != nil {
 		return
 	}
@@ -48,10 +45,10 @@ func (f *OCMLabelsPolicyBackend) Status(organizationId string, showClustersWitho
 	}
 	clusters.SortClusters(clusterInfos)
 
-	sectors, err = listSectorsFromOrganizationLabels(organization.ID(), connection)
+	sectors, err = listSectorsFromOrganizationLabels(ctx, organization.ID(), f.connection)
 	if err != nil {
 		return
 	}
-	inheritance, err = listVersionDataInheritanceConfiguration(organization.ID(), connection)
+	inheritance, err = listVersionDataInheritanceConfiguration(ctx, organization.ID(), f.connection)
 	return
 }
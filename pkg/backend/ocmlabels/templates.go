@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocmlabels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/clusters"
+	"github.com/app-sre/aus-cli/pkg/output"
+	"github.com/app-sre/aus-cli/pkg/policy"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+var POLICY_TEMPLATE_LABEL_KEY_PREFIX = newAusLabelKey("policy-template.")
+
+func (f *OCMLabelsPolicyBackend) ListPolicyTemplates(ctx context.Context, organizationId string) ([]policy.PolicyTemplate, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	return listPolicyTemplatesFromOrganizationLabels(ctx, organizationId, f.connection)
+}
+
+func (f *OCMLabelsPolicyBackend) ApplyPolicyTemplates(ctx context.Context, organizationId string, templates []policy.PolicyTemplate, dumpTemplates bool, dryRun bool) error {
+	if dumpTemplates {
+		body, err := json.Marshal(templates)
+		if err != nil {
+			return err
+		}
+		return output.PrettyList(os.Stdout, body)
+	}
+
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	output.Log(dryRun, "Apply policy templates to organization %s\n", organizationId)
+	templateLabels, err := listOrganizationLabels(ctx, organizationId, POLICY_TEMPLATE_LABEL_KEY_PREFIX, f.connection)
+	if err != nil {
+		return err
+	}
+	labelsContainer := NewOCMLabelsContainer(templateLabels)
+	for _, template := range templates {
+		value, err := json.Marshal(template)
+		if err != nil {
+			return err
+		}
+		label, err := buildOCMLabel(POLICY_TEMPLATE_LABEL_KEY_PREFIX+template.Name, string(value), "", organizationId)
+		if err != nil {
+			return err
+		}
+		labelsContainer.AddLabel(label)
+	}
+	return labelsContainer.Reconcile(ctx, dryRun, f.connection)
+}
+
+func (f *OCMLabelsPolicyBackend) RenderPolicyTemplates(ctx context.Context, organizationId string, templates []policy.PolicyTemplate) ([]policy.ClusterUpgradePolicy, error) {
+	organizationId, err := f.organizationId(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	clusterInfos, err := getClusterInfos(ctx, organizationId, "", f.connection)
+	if err != nil {
+		return nil, err
+	}
+	return clusters.RenderPolicyTemplates(clusterInfos, templates)
+}
+
+func listPolicyTemplatesFromOrganizationLabels(ctx context.Context, organizationId string, connection *sdk.Connection) ([]policy.PolicyTemplate, error) {
+	labels, err := listOrganizationLabels(ctx, organizationId, POLICY_TEMPLATE_LABEL_KEY_PREFIX, connection)
+	if err != nil {
+		return nil, err
+	}
+	templates := []policy.PolicyTemplate{}
+	for _, label := range labels {
+		var template policy.PolicyTemplate
+		err := json.Unmarshal([]byte(label.Value()), &template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode label %s: %v", label.Key(), err)
+		}
+		template.Name = strings.TrimPrefix(label.Key(), POLICY_TEMPLATE_LABEL_KEY_PREFIX)
+		templates = append(templates, template)
+	}
+	policy.SortPolicyTemplates(templates)
+	return templates, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocmlabels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/output"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// OCMLabelsTransaction reconciles a set of label containers as a unit. If reconciling any of them
+// fails, all label changes done so far, across all containers, are reverted.
+type OCMLabelsTransaction struct {
+	containers []*OCMLabelsContainer
+	limiter    *ocm.RateLimiter
+}
+
+// RollbackError is returned when a transaction failed and its changes have been rolled back. It
+// lists the labels that were restored and the ones that could not be restored.
+type RollbackError struct {
+	Err         error
+	Restored    []string
+	NotRestored []string
+}
+
+func (e *RollbackError) Error() string {
+	message := fmt.Sprintf("%v, rolled back %d label change(s)", e.Err, len(e.Restored))
+	if len(e.NotRestored) > 0 {
+		message += fmt.Sprintf(", failed to roll back: %s", strings.Join(e.NotRestored, "; "))
+	}
+	return message
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+func NewOCMLabelsTransaction() *OCMLabelsTransaction {
+	return &OCMLabelsTransaction{}
+}
+
+func (t *OCMLabelsTransaction) Add(lc *OCMLabelsContainer) {
+	t.containers = append(t.containers, lc)
+}
+
+// RateLimit limits the rate of label write requests done by the transaction.
+func (t *OCMLabelsTransaction) RateLimit(limiter *ocm.RateLimiter) {
+	t.limiter = limiter
+}
+
+func (t *OCMLabelsTransaction) Commit(ctx context.Context, dryRun bool, connection *sdk.Connection) error {
+	journal := &labelsJournal{}
+	for _, lc := range t.containers {
+		err := lc.reconcile(ctx, dryRun, connection, journal, t.limiter)
+		if err != nil {
+			if dryRun {
+				return err
+			}
+			// roll back even if the context has been cancelled
+			return journal.rollback(context.WithoutCancel(ctx), err, connection)
+		}
+	}
+	return nil
+}
+
+// labelChange describes a single label modification. original is nil if the label was created and
+// applied is nil if the label was deleted.
+type labelChange struct {
+	original *amv1.Label
+	applied  *amv1.Label
+}
+
+type labelsJournal struct {
+	changes []labelChange
+}
+
+func (j *labelsJournal) record(original *amv1.Label, applied *amv1.Label) {
+	if original != nil && applied != nil && original.Value() == applied.Value() {
+		return
+	}
+	j.changes = append(j.changes, labelChange{original: original, applied: applied})
+}
+
+// rollback reverts the recorded changes in reverse order.
+func (j *labelsJournal) rollback(ctx context.Context, cause error, connection *sdk.Connection) error {
+	rollbackErr := &RollbackError{Err: cause}
+	output.Log(false, "Failed to apply labels (%v), rolling back %d change(s)\n", cause, len(j.changes))
+	for i := len(j.changes) - 1; i >= 0; i-- {
+		change := j.changes[i]
+		var description string
+		var err error
+		if change.original == nil {
+			description = fmt.Sprintf("removed label %s from %s", change.applied.Key(), labelTarget(change.applied))
+			err = deleteOCMLabel(ctx, change.applied, false, connection)
+		} else {
+			description = fmt.Sprintf("restored label %s=%s on %s", change.original.Key(), change.original.Value(), labelTarget(change.original))
+			var restore *amv1.Label
+			restore, err = buildOCMLabel(change.original.Key(), change.original.Value(), change.original.SubscriptionID(), change.original.OrganizationID())
+			if err == nil {
+				_, err = applyOCMLabel(ctx, restore, false, connection)
+			}
+		}
+		if err != nil {
+			rollbackErr.NotRestored = append(rollbackErr.NotRestored, fmt.Sprintf("%s (%v)", description, err))
+			continue
+		}
+		output.Log(false, "Rollback: %s\n", description)
+		rollbackErr.Restored = append(rollbackErr.Restored, description)
+	}
+	return rollbackErr
+}
+
+func labelTarget(label *amv1.Label) string {
+	if label.SubscriptionID() != "" {
+		return fmt.Sprintf("subscription %s", label.SubscriptionID())
+	}
+	return fmt.Sprintf("organization %s", label.OrganizationID())
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package ocmlabels
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/app-sre/aus-cli/pkg/clusters"
+	"github.com/app-sre/aus-cli/pkg/policy"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 )
 
@@ -27,17 +30,37 @@ func newAusLabelKey(suffix string) string {
 	return fmt.Sprintf("sre-capabilities.aus.%s", suffix)
 }
 
-func getClusterInfos(organizationId string, subscriptionSearchQuery string, connection *sdk.Connection) ([]*clusters.ClusterInfo, error) {
-	clusterInfos, err := clusters.ClusterInfosForOrganization(organizationId, subscriptionSearchQuery, false, connection)
+// warn reports a problem that doesn't stop the command, like an invalid label of a cluster.
+func warn(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", a...)
+}
+
+func getClusterInfos(ctx context.Context, organizationId string, subscriptionSearchQuery string, connection *sdk.Connection) ([]*clusters.ClusterInfo, error) {
+	clusterInfos, err := clusters.ClusterInfosForOrganization(ctx, organizationId, subscriptionSearchQuery, false, connection)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := getPolicyDefaults(ctx, organizationId, connection)
+	if err != nil {
+		return nil, err
+	}
+	sectorApprovals, err := getSectorApprovals(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
 	for _, clusterInfo := range clusterInfos {
-		policy, err := getPolicyForSubscription(clusterInfo.Subscription, clusterInfo.Cluster)
+		clusterPolicy, err := getPolicyForSubscription(clusterInfo.Subscription, clusterInfo.Cluster, defaults)
 		if err != nil {
-			return nil, err
+			// an invalid label of one cluster must not hide the others, the cluster is shown without policy
+			warn("ignoring the invalid policy of cluster %s: %v", clusterInfo.Subscription.DisplayName(), err)
+			clusterPolicy = &policy.ClusterUpgradePolicy{ClusterName: clusterInfo.Subscription.DisplayName()}
+		}
+		clusterInfo.Policy = clusterPolicy
+		clusterInfo.Approvals = getSubscriptionApprovals(clusterInfo.Subscription)
+		if clusterPolicy.Conditions.Sector != "" {
+			clusterInfo.Approvals = append(clusterInfo.Approvals, sectorApprovals[clusterPolicy.Conditions.Sector]...)
 		}
-		clusterInfo.Policy = policy
 	}
+	clusters.LinkCanaries(clusterInfos)
 	return clusterInfos, nil
 }
@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"github.com/app-sre/aus-cli/pkg/ocm"
+	"github.com/app-sre/aus-cli/pkg/policy"
+)
+
+// NewOwnerGuard returns a guard for the team of the caller. With force, the team isn't looked up
+// and policies of all teams can be changed.
+func NewOwnerGuard(force bool) (policy.OwnerGuard, error) {
+	if force {
+		return policy.OwnerGuard{Force: true}, nil
+	}
+	team, err := ocm.CallerTeam()
+	if err != nil {
+		return policy.OwnerGuard{}, err
+	}
+	return policy.OwnerGuard{Team: team}, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/app-sre/aus-cli/pkg/policy"
+)
+
+// LoadPolicyTemplates reads the policy templates from the given file or, if no file is given, from
+// the organization. If names are given, only these templates are returned.
+func LoadPolicyTemplates(ctx context.Context, be PolicyBackend, organizationId string, templateFile string, names []string) ([]policy.PolicyTemplate, error) {
+	var templates []policy.PolicyTemplate
+	if templateFile != "" {
+		file, err := os.Open(templateFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		templates, err = policy.NewPolicyTemplatesFromReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", templateFile, err)
+		}
+	} else {
+		var err error
+		templates, err = be.ListPolicyTemplates(ctx, organizationId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(names) == 0 {
+		return templates, nil
+	}
+
+	byName := make(map[string]policy.PolicyTemplate)
+	for _, template := range templates {
+		byName[template.Name] = template
+	}
+	selected := []policy.PolicyTemplate{}
+	for _, name := range names {
+		template, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("policy template %s not found", name)
+		}
+		selected = append(selected, template)
+	}
+	return selected, nil
+}
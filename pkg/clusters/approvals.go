@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import "regexp"
+
+// Approved returns true if the version was approved for the cluster or for its sector.
+func (c *ClusterInfo) Approved(version string) bool {
+	for _, approval := range c.Approvals {
+		if approval.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingApprovals returns the available upgrades of a cluster that require an approval and
+// haven't been approved yet.
+func (c *ClusterInfo) PendingApprovals(additionalBlockedVersions []*regexp.Regexp) []string {
+	if c.Policy == nil || !c.Policy.Conditions.RequireApproval {
+		return nil
+	}
+	pending := []string{}
+	for _, version := range c.AvailableUpgrades(false, additionalBlockedVersions) {
+		if !c.Approved(version) {
+			pending = append(pending, version)
+		}
+	}
+	return pending
+}
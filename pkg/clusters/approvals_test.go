@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/app-sre/aus-cli/pkg/policy"
+)
+
+func TestPendingApprovals(t *testing.T) {
+	approvals := []policy.Approval{
+		{Version: "4.14.2", Approver: "someone"},
+		{Version: "4.14.3", Sector: "prod", Approver: "someone"},
+	}
+	withConditions := func(conditions policy.ClusterUpgradePolicyConditions) *policy.ClusterUpgradePolicy {
+		p := testPolicy("cluster", false, "w1")
+		p.Conditions = conditions
+		return p
+	}
+	tests := []struct {
+		name             string
+		policy           *policy.ClusterUpgradePolicy
+		blockedVersions  []*regexp.Regexp
+		expectedApproved []string
+		expectedPending  []string
+	}{
+		{
+			name:             "no approval required",
+			policy:           withConditions(policy.ClusterUpgradePolicyConditions{}),
+			expectedApproved: []string{"4.14.2", "4.14.3"},
+		},
+		{
+			name:             "approval required",
+			policy:           withConditions(policy.ClusterUpgradePolicyConditions{RequireApproval: true}),
+			expectedApproved: []string{"4.14.2", "4.14.3"},
+			expectedPending:  []string{"4.14.4", "4.15.0"},
+		},
+		{
+			name:             "blocked versions are not pending",
+			policy:           withConditions(policy.ClusterUpgradePolicyConditions{RequireApproval: true}),
+			blockedVersions:  []*regexp.Regexp{regexp.MustCompile(`^4\.14\.4$`)},
+			expectedApproved: []string{"4.14.2", "4.14.3"},
+			expectedPending:  []string{"4.15.0"},
+		},
+		{
+			name:             "versions beyond the max version are not pending",
+			policy:           withConditions(policy.ClusterUpgradePolicyConditions{RequireApproval: true, MaxVersion: "4.14"}),
+			expectedApproved: []string{"4.14.2", "4.14.3"},
+			expectedPending:  []string{"4.14.4"},
+		},
+		{
+			name:             "without policy",
+			expectedApproved: []string{"4.14.2", "4.14.3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := testClusterInfo(t, "cluster", "4.14.1", []string{"4.14.2", "4.14.3", "4.14.4", "4.15.0"}, tt.policy)
+			cluster.Approvals = approvals
+			approved := []string{}
+			for _, version := range []string{"4.14.2", "4.14.3", "4.14.4", "4.15.0"} {
+				if cluster.Approved(version) {
+					approved = append(approved, version)
+				}
+			}
+			if !reflect.DeepEqual(approved, tt.expectedApproved) {
+				t.Errorf("expected approved versions %v, got %v", tt.expectedApproved, approved)
+			}
+			pending := cluster.PendingApprovals(tt.blockedVersions)
+			if len(pending) != len(tt.expectedPending) || (len(pending) > 0 && !reflect.DeepEqual(pending, tt.expectedPending)) {
+				t.Errorf("expected pending approvals %v, got %v", tt.expectedPending, pending)
+			}
+		})
+	}
+}
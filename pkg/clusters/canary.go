@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	semver "github.com/Masterminds/semver/v3"
+)
+
+// LinkCanaries sets the canaries of every cluster, i.e. the canary clusters of the workloads the
+// cluster runs. Only clusters with a valid policy are considered.
+func LinkCanaries(infos []*ClusterInfo) {
+	canaries := map[string]*ClusterInfo{}
+	for _, info := range infos {
+		if !hasValidPolicy(info) || !info.Policy.Conditions.Canary {
+			continue
+		}
+		for _, workload := range info.Policy.Workloads {
+			canaries[workload] = info
+		}
+	}
+	for _, info := range infos {
+		info.Canaries = nil
+		if !hasValidPolicy(info) {
+			continue
+		}
+		for _, workload := range info.Policy.Workloads {
+			if canary, ok := canaries[workload]; ok && canary != info {
+				info.Canaries = append(info.Canaries, canary)
+			}
+		}
+	}
+}
+
+// heldBackByCanary returns true if a canary of the cluster doesn't run the version yet.
+func (c *ClusterInfo) heldBackByCanary(version string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, canary := range c.Canaries {
+		canaryVersion, err := semver.NewVersion(canary.Cluster.Version().RawID())
+		if err != nil {
+			continue
+		}
+		if v.GreaterThan(canaryVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// HeldBackUpgrades returns the available upgrades of the cluster that wait for its canaries.
+func (c *ClusterInfo) HeldBackUpgrades() []string {
+	heldBack := []string{}
+	for _, version := range c.Cluster.Version().AvailableUpgrades() {
+		if c.heldBackByCanary(version) {
+			heldBack = append(heldBack, version)
+		}
+	}
+	return heldBack
+}
+
+func hasValidPolicy(info *ClusterInfo) bool {
+	return info.Policy != nil && info.Policy.Validate() == nil
+}
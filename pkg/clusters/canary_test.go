@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/app-sre/aus-cli/pkg/policy"
+	csv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func testClusterInfo(t *testing.T, name string, version string, availableUpgrades []string, p *policy.ClusterUpgradePolicy) *ClusterInfo {
+	t.Helper()
+	cluster, err := csv1.NewCluster().
+		ID(name).
+		Name(name).
+		Version(csv1.NewVersion().RawID(version).AvailableUpgrades(availableUpgrades...)).
+		Build()
+	if err != nil {
+		t.Fatalf("can't build cluster: %v", err)
+	}
+	return &ClusterInfo{Cluster: cluster, Policy: p}
+}
+
+func testPolicy(name string, canary bool, workloads ...string) *policy.ClusterUpgradePolicy {
+	return &policy.ClusterUpgradePolicy{
+		ClusterName: name,
+		Schedule:    "* * * * *",
+		Workloads:   workloads,
+		Conditions:  policy.ClusterUpgradePolicyConditions{Canary: canary},
+	}
+}
+
+func TestLinkCanaries(t *testing.T) {
+	canary1 := testClusterInfo(t, "canary-1", "4.14.2", nil, testPolicy("canary-1", true, "w1"))
+	canary2 := testClusterInfo(t, "canary-2", "4.14.2", nil, testPolicy("canary-2", true, "w2"))
+	both := testClusterInfo(t, "both", "4.14.0", nil, testPolicy("both", false, "w1", "w2"))
+	other := testClusterInfo(t, "other", "4.14.0", nil, testPolicy("other", false, "w3"))
+	invalid := testClusterInfo(t, "invalid", "4.14.0", nil, &policy.ClusterUpgradePolicy{ClusterName: "invalid", Workloads: []string{"w1"}})
+	invalidCanary := testClusterInfo(t, "invalid-canary", "4.14.0", nil, &policy.ClusterUpgradePolicy{
+		ClusterName: "invalid-canary",
+		Workloads:   []string{"w3"},
+		Conditions:  policy.ClusterUpgradePolicyConditions{Canary: true},
+	})
+	withoutPolicy := testClusterInfo(t, "without-policy", "4.14.0", nil, nil)
+
+	LinkCanaries([]*ClusterInfo{canary1, canary2, both, other, invalid, invalidCanary, withoutPolicy})
+
+	tests := []struct {
+		info     *ClusterInfo
+		expected []*ClusterInfo
+	}{
+		{canary1, nil},
+		{canary2, nil},
+		{both, []*ClusterInfo{canary1, canary2}},
+		{other, nil},
+		{invalid, nil},
+		{invalidCanary, nil},
+		{withoutPolicy, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.info.Cluster.Name(), func(t *testing.T) {
+			if !reflect.DeepEqual(tt.info.Canaries, tt.expected) {
+				t.Errorf("expected %d canaries, got %d", len(tt.expected), len(tt.info.Canaries))
+			}
+		})
+	}
+}
+
+func TestHeldBackUpgrades(t *testing.T) {
+	upgrades := []string{"4.14.1", "4.14.2", "4.15.3", "4.16.0"}
+	tests := []struct {
+		name              string
+		canaryVersions    []string
+		expectedHeldBack  []string
+		expectedAvailable []string
+	}{
+		{
+			name:              "no canary",
+			canaryVersions:    nil,
+			expectedHeldBack:  []string{},
+			expectedAvailable: upgrades,
+		},
+		{
+			name:              "canary on the current version",
+			canaryVersions:    []string{"4.14.0"},
+			expectedHeldBack:  upgrades,
+			expectedAvailable: nil,
+		},
+		{
+			name:              "canary ahead",
+			canaryVersions:    []string{"4.14.2"},
+			expectedHeldBack:  []string{"4.15.3", "4.16.0"},
+			expectedAvailable: []string{"4.14.1", "4.14.2"},
+		},
+		{
+			name:              "the slowest canary wins",
+			canaryVersions:    []string{"4.16.0", "4.14.1"},
+			expectedHeldBack:  []string{"4.14.2", "4.15.3", "4.16.0"},
+			expectedAvailable: []string{"4.14.1"},
+		},
+		{
+			name:              "canary with an invalid version",
+			canaryVersions:    []string{"unknown"},
+			expectedHeldBack:  []string{},
+			expectedAvailable: upgrades,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos := []*ClusterInfo{testClusterInfo(t, "cluster", "4.14.0", upgrades, testPolicy("cluster", false, "w1", "w2"))}
+			for i, version := range tt.canaryVersions {
+				workload := []string{"w1", "w2"}[i]
+				infos = append(infos, testClusterInfo(t, "canary-"+workload, version, nil, testPolicy("canary-"+workload, true, workload)))
+			}
+			LinkCanaries(infos)
+			cluster := infos[0]
+			if heldBack := cluster.HeldBackUpgrades(); !reflect.DeepEqual(heldBack, tt.expectedHeldBack) {
+				t.Errorf("expected held back upgrades %v, got %v", tt.expectedHeldBack, heldBack)
+			}
+			if available := cluster.AvailableUpgrades(false, nil); !reflect.DeepEqual(available, tt.expectedAvailable) {
+				t.Errorf("expected available upgrades %v, got %v", tt.expectedAvailable, available)
+			}
+		})
+	}
+}
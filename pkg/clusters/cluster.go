@@ -17,6 +17,7 @@ limitations under the License.
 package clusters
 
 import (
+	"context"
 	"sort"
 
 	"github.com/app-sre/aus-cli/pkg/ocm"
@@ -31,6 +32,24 @@ type ClusterInfo struct {
 	Cluster               *csv1.Cluster
 	VersionGateAgreements *map[string]*csv1.VersionGateAgreement
 	Policy                *policy.ClusterUpgradePolicy
+	// Approvals holds the versions approved for the cluster or its sector.
+	Approvals []policy.Approval
+	// Canaries holds the canary clusters of the workloads of the cluster, see LinkCanaries.
+	Canaries []*ClusterInfo
+}
+
+// DisplayName returns the name policies refer to the cluster by, i.e. the display name of its
+// subscription. It can differ from the name of the cluster.
+func (c *ClusterInfo) DisplayName() string {
+	if c.Subscription == nil {
+		return c.Cluster.Name()
+	}
+	return c.Subscription.DisplayName()
+}
+
+// OwnedBy reports whether the policy of the cluster is owned by the team.
+func (c *ClusterInfo) OwnedBy(team string) bool {
+	return c.Policy != nil && c.Policy.Owner == team
 }
 
 func (c *ClusterInfo) STSEnabled() bool {
@@ -51,12 +70,12 @@ func SortClusters(clusters []*ClusterInfo) {
 	})
 }
 
-func GetClusterInfoByName(organizationID string, clusterName string, connection *sdk.Connection) (*ClusterInfo, error) {
-	cluster, err := ocm.GetClusterByName(organizationID, clusterName, connection)
+func GetClusterInfoByName(ctx context.Context, organizationID string, clusterName string, connection *sdk.Connection) (*ClusterInfo, error) {
+	cluster, err := ocm.GetClusterByName(ctx, organizationID, clusterName, connection)
 	if err != nil {
 		return nil, err
 	}
-	agreements, err := ocm.GetVersionGateAgreements(cluster.ID(), connection)
+	agreements, err := ocm.GetVersionGateAgreements(ctx, cluster.ID(), connection)
 	if err != nil {
 		return nil, err
 	}
@@ -66,12 +85,12 @@ func GetClusterInfoByName(organizationID string, clusterName string, connection
 	}, nil
 }
 
-func ClusterInfosForOrganization(organizationId string, subscriptionSearchQuery string, withAgreements bool, connection *sdk.Connection) ([]*ClusterInfo, error) {
-	subscriptions, err := ocm.SubscriptionsForOrganization(organizationId, subscriptionSearchQuery, connection)
+func ClusterInfosForOrganization(ctx context.Context, organizationId string, subscriptionSearchQuery string, withAgreements bool, connection *sdk.Connection) ([]*ClusterInfo, error) {
+	subscriptions, err := ocm.SubscriptionsForOrganization(ctx, organizationId, subscriptionSearchQuery, connection)
 	if err != nil {
 		return nil, err
 	}
-	clusterMap, err := ocm.ClustersForOrganization(organizationId, connection)
+	clusterMap, err := ocm.ClustersForOrganization(ctx, organizationId, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +103,7 @@ func ClusterInfosForOrganization(organizationId string, subscriptionSearchQuery
 		}
 		var agreements *map[string]*csv1.VersionGateAgreement
 		if withAgreements {
-			agreements_map, err := ocm.GetVersionGateAgreements(subscription.ClusterID(), connection)
+			agreements_map, err := ocm.GetVersionGateAgreements(ctx, subscription.ClusterID(), connection)
 			if err != nil {
 				return nil, err
 			}
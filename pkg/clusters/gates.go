@@ -17,14 +17,16 @@ limitations under the License.
 package clusters
 
 import (
+	"context"
+
 	"github.com/app-sre/aus-cli/pkg/ocm"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	csv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
-func AckAllGatesForYStream(cluster *ClusterInfo, yStream string, connection *sdk.Connection, dryRun bool) ([]*csv1.VersionGateAgreement, error) {
+func AckAllGatesForYStream(ctx context.Context, cluster *ClusterInfo, yStream string, connection *sdk.Connection, dryRun bool) ([]*csv1.VersionGateAgreement, error) {
 	agreements := []*csv1.VersionGateAgreement{}
-	gates, err := ocm.GetVersionGates(connection)
+	gates, err := ocm.GetVersionGates(ctx, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +36,7 @@ func AckAllGatesForYStream(cluster *ClusterInfo, yStream string, connection *sdk
 	}
 	for _, gate := range unackedGates {
 		if gate.VersionRawIDPrefix() == yStream {
-			agreement, err := ocm.AckVersionGate(cluster.Cluster, gate, connection, dryRun)
+			agreement, err := ocm.AckVersionGate(ctx, cluster.Cluster, gate, connection, dryRun)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/utils"
+)
+
+const (
+	NameAttribute          = "name"
+	CloudProviderAttribute = "cloud_provider"
+	RegionAttribute        = "region"
+	ProductAttribute       = "product"
+	ChannelGroupAttribute  = "channel_group"
+)
+
+var supportedAttributes = []string{
+	CloudProviderAttribute,
+	RegionAttribute,
+	ProductAttribute,
+	ChannelGroupAttribute,
+}
+
+// subscription fields that can be used to narrow down the subscription search
+var attributeSubscriptionFields = map[string]string{
+	CloudProviderAttribute: "cloud_provider_id",
+	RegionAttribute:        "region_id",
+}
+
+// Selector selects clusters by their upgrade policy, cluster attributes and subscription labels.
+// Empty fields match every cluster, all given fields need to match.
+type Selector struct {
+	Sector   string
+	Workload string
+	// Name is a glob pattern for the display name of the cluster, e.g. "prod-*".
+	Name string
+	// Attributes are cluster attributes like the region, see supportedAttributes.
+	Attributes map[string]string
+	Labels     map[string]string
+}
+
+// NewSelector builds a selector out of key=value pairs. The name key is a glob pattern for the
+// display name of the cluster, keys naming a cluster attribute match the attribute and all other keys match
+// subscription labels.
+func NewSelector(values map[string]string) Selector {
+	selector := Selector{
+		Attributes: make(map[string]string),
+		Labels:     make(map[string]string),
+	}
+	for key, value := range values {
+		if key == NameAttribute {
+			selector.Name = value
+		} else if utils.StringInArray(supportedAttributes, key) {
+			selector.Attributes[key] = value
+		} else {
+			selector.Labels[key] = value
+		}
+	}
+	return selector
+}
+
+// ParseSelector parses a comma separated list of key=value pairs, e.g. "region=us-east-1,product=rosa".
+func ParseSelector(value string) (map[string]string, error) {
+	return ParseLabelSelectors(strings.Split(value, ","))
+}
+
+// ParseLabelSelectors parses label selectors in the key=value format.
+func ParseLabelSelectors(values []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, value := range values {
+		key, labelValue, found := strings.Cut(value, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid label selector %s, expected key=value", value)
+		}
+		labels[key] = labelValue
+	}
+	return labels, nil
+}
+
+func (s Selector) Empty() bool {
+	return s.Sector == "" && s.Workload == "" && s.Name == "" && len(s.Attributes) == 0 && len(s.Labels) == 0
+}
+
+// SubscriptionSearchQuery returns a subscription search query for the attributes that are
+// available on subscriptions. The remaining attributes and labels are only checked by Matches.
+func (s Selector) SubscriptionSearchQuery() string {
+	query := "managed = true and status in ('Active', 'Reserved')"
+	for _, attribute := range supportedAttributes {
+		field, ok := attributeSubscriptionFields[attribute]
+		value, selected := s.Attributes[attribute]
+		if ok && selected {
+			query += fmt.Sprintf(" and %s = '%s'", field, strings.ReplaceAll(value, "'", "''"))
+		}
+	}
+	return query
+}
+
+func (s Selector) String() string {
+	pairs := []string{}
+	if s.Name != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", NameAttribute, s.Name))
+	}
+	for key, value := range s.Attributes {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	for key, value := range s.Labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (s Selector) Validate() error {
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return fmt.Errorf("invalid cluster name pattern %s: %v", s.Name, err)
+	}
+	return nil
+}
+
+func (s Selector) Matches(cluster *ClusterInfo) bool {
+	if s.Name != "" {
+		if matched, _ := path.Match(s.Name, cluster.DisplayName()); !matched {
+			return false
+		}
+	}
+	if s.Sector != "" && (cluster.Policy == nil || cluster.Policy.Conditions.Sector != s.Sector) {
+		return false
+	}
+	if s.Workload != "" && (cluster.Policy == nil || !utils.StringInArray(cluster.Policy.Workloads, s.Workload)) {
+		return false
+	}
+	for attribute, value := range s.Attributes {
+		if clusterAttribute(cluster, attribute) != value {
+			return false
+		}
+	}
+	for key, value := range s.Labels {
+		if !subscriptionHasLabel(cluster, key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectClusters returns the clusters matching the selector, sorted by name.
+func SelectClusters(clusterInfos []*ClusterInfo, selector Selector) []*ClusterInfo {
+	selected := []*ClusterInfo{}
+	for _, cluster := range clusterInfos {
+		if selector.Matches(cluster) {
+			selected = append(selected, cluster)
+		}
+	}
+	SortClusters(selected)
+	return selected
+}
+
+func clusterAttribute(cluster *ClusterInfo, attribute string) string {
+	switch attribute {
+	case CloudProviderAttribute:
+		return cluster.Cluster.CloudProvider().ID()
+	case RegionAttribute:
+		return cluster.Cluster.Region().ID()
+	case ProductAttribute:
+		return cluster.Cluster.Product().ID()
+	case ChannelGroupAttribute:
+		return cluster.Cluster.Version().ChannelGroup()
+	}
+	return ""
+}
+
+func subscriptionHasLabel(cluster *ClusterInfo, key string, value string) bool {
+	if cluster.Subscription == nil {
+		return false
+	}
+	for _, label := range cluster.Subscription.Labels() {
+		if label.Key() == key && label.Value() == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/app-sre/aus-cli/pkg/policy"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	csv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func selectorTestCluster(t *testing.T, clusterName string, displayName string, region string, labels map[string]string, p *policy.ClusterUpgradePolicy) *ClusterInfo {
+	t.Helper()
+	cluster, err := csv1.NewCluster().
+		Name(clusterName).
+		Region(csv1.NewCloudRegion().ID(region)).
+		CloudProvider(csv1.NewCloudProvider().ID("aws")).
+		Build()
+	if err != nil {
+		t.Fatalf("can't build cluster: %v", err)
+	}
+	subscriptionLabels := []*amv1.LabelBuilder{}
+	for key, value := range labels {
+		subscriptionLabels = append(subscriptionLabels, amv1.NewLabel().Key(key).Value(value))
+	}
+	subscription, err := amv1.NewSubscription().DisplayName(displayName).Labels(subscriptionLabels...).Build()
+	if err != nil {
+		t.Fatalf("can't build subscription: %v", err)
+	}
+	return &ClusterInfo{Cluster: cluster, Subscription: subscription, Policy: p}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	prod := selectorTestCluster(t, "a1b2c3", "prod-1", "us-east-1", map[string]string{"env": "prod"}, &policy.ClusterUpgradePolicy{
+		Workloads:  []string{"w1", "w2"},
+		Conditions: policy.ClusterUpgradePolicyConditions{Sector: "prod"},
+	})
+	stage := selectorTestCluster(t, "d4e5f6", "stage-1", "eu-west-1", map[string]string{"env": "stage"}, nil)
+	tests := []struct {
+		name     string
+		selector Selector
+		expected []*ClusterInfo
+	}{
+		{"empty", Selector{}, []*ClusterInfo{prod, stage}},
+		{"display name glob", Selector{Name: "prod-*"}, []*ClusterInfo{prod}},
+		{"cluster names are not matched", Selector{Name: "a1*"}, []*ClusterInfo{}},
+		{"sector", Selector{Sector: "prod"}, []*ClusterInfo{prod}},
+		{"workload", Selector{Workload: "w2"}, []*ClusterInfo{prod}},
+		{"unknown workload", Selector{Workload: "w3"}, []*ClusterInfo{}},
+		{"attribute", NewSelector(map[string]string{"region": "eu-west-1"}), []*ClusterInfo{stage}},
+		{"label", NewSelector(map[string]string{"env": "stage"}), []*ClusterInfo{stage}},
+		{"all fields", Selector{Name: "prod-?", Sector: "prod", Attributes: map[string]string{"cloud_provider": "aws"}, Labels: map[string]string{"env": "prod"}}, []*ClusterInfo{prod}},
+		{"one field doesn't match", Selector{Name: "prod-?", Labels: map[string]string{"env": "stage"}}, []*ClusterInfo{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := SelectClusters([]*ClusterInfo{stage, prod}, tt.selector)
+			if !reflect.DeepEqual(selected, tt.expected) {
+				names := []string{}
+				for _, info := range selected {
+					names = append(names, info.DisplayName())
+				}
+				t.Errorf("expected %d clusters, got %v", len(tt.expected), names)
+			}
+		})
+	}
+}
+
+func TestNewSelector(t *testing.T) {
+	values, err := ParseSelector("name=prod-*,region=us-east-1,product=rosa,env=prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	selector := NewSelector(values)
+	expected := Selector{
+		Name:       "prod-*",
+		Attributes: map[string]string{"region": "us-east-1", "product": "rosa"},
+		Labels:     map[string]string{"env": "prod"},
+	}
+	if !reflect.DeepEqual(selector, expected) {
+		t.Errorf("expected %+v, got %+v", expected, selector)
+	}
+	if selector.String() != "env=prod,name=prod-*,product=rosa,region=us-east-1" {
+		t.Errorf("unexpected string %s", selector.String())
+	}
+	if query := selector.SubscriptionSearchQuery(); query != "managed = true and status in ('Active', 'Reserved') and region_id = 'us-east-1'" {
+		t.Errorf("unexpected search query %s", query)
+	}
+}
+
+func TestParseLabelSelectors(t *testing.T) {
+	tests := []struct {
+		values      []string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		{[]string{"env=prod"}, map[string]string{"env": "prod"}, false},
+		{[]string{"env="}, map[string]string{"env": ""}, false},
+		{[]string{"a=b=c"}, map[string]string{"a": "b=c"}, false},
+		{[]string{"env"}, nil, true},
+		{[]string{"=prod"}, nil, true},
+	}
+	for _, tt := range tests {
+		labels, err := ParseLabelSelectors(tt.values)
+		if (err != nil) != tt.expectedErr {
+			t.Errorf("%v: unexpected error %v", tt.values, err)
+			continue
+		}
+		if !tt.expectedErr && !reflect.DeepEqual(labels, tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.values, tt.expected, labels)
+		}
+	}
+}
+
+func TestSelectorValidate(t *testing.T) {
+	if err := (Selector{Name: "prod-[1-"}).Validate(); err == nil {
+		t.Errorf("expected an invalid pattern to fail")
+	}
+	if err := (Selector{Name: "prod-[1-3]"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
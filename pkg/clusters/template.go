@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"fmt"
+
+	"github.com/app-sre/aus-cli/pkg/policy"
+)
+
+func (c *ClusterInfo) TemplateData() policy.TemplateData {
+	labels := make(map[string]string)
+	if c.Subscription != nil {
+		for _, label := range c.Subscription.Labels() {
+			labels[label.Key()] = label.Value()
+		}
+	}
+	return policy.TemplateData{
+		Name:          c.DisplayName(),
+		ID:            c.Cluster.ID(),
+		CloudProvider: clusterAttribute(c, CloudProviderAttribute),
+		Region:        clusterAttribute(c, RegionAttribute),
+		Product:       clusterAttribute(c, ProductAttribute),
+		ChannelGroup:  clusterAttribute(c, ChannelGroupAttribute),
+		Labels:        labels,
+	}
+}
+
+// RenderPolicyTemplates renders every template for each cluster matching its selector. A cluster
+// matched by more than one template is an error.
+func RenderPolicyTemplates(clusterInfos []*ClusterInfo, templates []policy.PolicyTemplate) ([]policy.ClusterUpgradePolicy, error) {
+	policies := []policy.ClusterUpgradePolicy{}
+	renderedBy := make(map[string]string)
+	for _, template := range templates {
+		selector := NewSelector(template.Selector)
+		if err := selector.Validate(); err != nil {
+			return nil, fmt.Errorf("template %s: %v", template.Name, err)
+		}
+		for _, clusterInfo := range SelectClusters(clusterInfos, selector) {
+			data := clusterInfo.TemplateData()
+			if other, ok := renderedBy[data.Name]; ok {
+				return nil, fmt.Errorf("cluster %s is matched by template %s and template %s", data.Name, other, template.Name)
+			}
+			renderedBy[data.Name] = template.Name
+			clusterPolicy, err := template.Render(data, false)
+			if err != nil {
+				return nil, err
+			}
+			if err := clusterPolicy.ValidateExplicit(); err != nil {
+				return nil, fmt.Errorf("template %s for cluster %s: %v", template.Name, data.Name, err)
+			}
+			policies = append(policies, clusterPolicy)
+		}
+	}
+	policy.SortPolicies(policies)
+	return policies, nil
+}
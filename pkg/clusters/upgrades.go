@@ -51,6 +51,18 @@ func (c *ClusterInfo) AvailableUpgrades(considerBlockedVersions bool, additional
 		if versions.IsVersionBlocked(version, additionalBlockedVersions) {
 			continue
 		}
+		// check if the version is beyond the maximum version of the cluster
+		if versions.ExceedsMaxVersion(version, c.Policy.Conditions.MaxVersion) {
+			continue
+		}
+		// check if the cluster is restricted to patch upgrades
+		if c.Policy.Conditions.ZStreamOnly && versions.ExceedsMaxVersion(version, c.Cluster.Version().RawID()) {
+			continue
+		}
+		// check if the canaries of the cluster run the version already
+		if c.heldBackByCanary(version) {
+			continue
+		}
 		upgrades = append(upgrades, version)
 	}
 	return upgrades
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAvailableUpgradesZStreamOnly(t *testing.T) {
+	upgrades := []string{"4.14.1", "4.14.2", "4.15.0", "5.0.0"}
+	tests := []struct {
+		name        string
+		zStreamOnly bool
+		expected    []string
+	}{
+		{
+			name:     "all upgrades",
+			expected: upgrades,
+		},
+		{
+			name:        "patch upgrades only",
+			zStreamOnly: true,
+			expected:    []string{"4.14.1", "4.14.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPolicy("cluster", false, "w1")
+			p.Conditions.ZStreamOnly = tt.zStreamOnly
+			cluster := testClusterInfo(t, "cluster", "4.14.0", upgrades, p)
+			if available := cluster.AvailableUpgrades(false, nil); !reflect.DeepEqual(available, tt.expected) {
+				t.Errorf("expected available upgrades %v, got %v", tt.expected, available)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// ErrCancelled is returned if the document was not changed or was emptied in the editor.
+var ErrCancelled = errors.New("edit cancelled, no changes made")
+
+// Editor edits documents in the editor of the user, given by $VISUAL or $EDITOR.
+type Editor struct {
+	format string
+}
+
+func NewEditor(format string) (*Editor, error) {
+	if format != FormatYAML && format != FormatJSON {
+		return nil, fmt.Errorf("unsupported format %s, supported are %s and %s", format, FormatYAML, FormatJSON)
+	}
+	return &Editor{format: format}, nil
+}
+
+// Edit opens the value in the editor and decodes the result into edited. The comments are shown
+// above the document and the validate function is called with the decoded result. If decoding or
+// validation fails, the editor is opened again with the error on top, until the document is valid,
+// unchanged or empty.
+func (e *Editor) Edit(name string, comments []string, value interface{}, edited interface{}, validate func() error) (original string, result string, err error) {
+	original, err = e.marshal(value)
+	if err != nil {
+		return "", "", err
+	}
+	file, err := os.CreateTemp("", fmt.Sprintf("ocm-aus-%s-*.%s", name, e.format))
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+
+	content := original
+	instructions := append([]string{
+		"Please edit the document below. Lines beginning with a '#' are ignored,",
+		"and an empty file aborts the edit.",
+	}, comments...)
+	header := instructions
+	for {
+		err = os.WriteFile(file.Name(), []byte(commentLines(header)+content), 0600)
+		if err != nil {
+			return "", "", err
+		}
+		err = run(file.Name())
+		if err != nil {
+			return "", "", err
+		}
+		body, err := os.ReadFile(file.Name())
+		if err != nil {
+			return "", "", err
+		}
+		result = stripComments(string(body))
+		if strings.TrimSpace(result) == "" || result == original {
+			return "", "", ErrCancelled
+		}
+		problem := e.unmarshal(result, edited)
+		if problem == nil {
+			problem = validate()
+		}
+		if problem == nil {
+			return original, result, nil
+		}
+		if result == content {
+			// the same invalid document was saved twice
+			return "", "", fmt.Errorf("edited document is invalid: %v", problem)
+		}
+		header = append([]string{fmt.Sprintf("The edited document is invalid: %v", problem), ""}, instructions...)
+		content = result
+	}
+}
+
+// Diff returns a unified diff between two documents.
+func Diff(original string, edited string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(original, "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(edited, "\n")),
+		FromFile: "current",
+		ToFile:   "edited",
+		Context:  3,
+	})
+}
+
+func (e *Editor) marshal(value interface{}) (string, error) {
+	body, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if e.format == FormatJSON {
+		return string(body) + "\n", nil
+	}
+	// JSON is valid YAML, decoding it into a node keeps the JSON field names and their order
+	var node yaml.Node
+	err = yaml.Unmarshal(body, &node)
+	if err != nil {
+		return "", err
+	}
+	blockStyle(&node)
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&node)
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func (e *Editor) unmarshal(document string, value interface{}) error {
+	body := []byte(document)
+	if e.format == FormatYAML {
+		var generic interface{}
+		decoder := yaml.NewDecoder(bytes.NewReader(body))
+		err := decoder.Decode(&generic)
+		if err != nil {
+			return err
+		}
+		if err := decoder.Decode(&generic); err != io.EOF {
+			return fmt.Errorf("unexpected content after the document: %v", err)
+		}
+		body, err = json.Marshal(generic)
+		if err != nil {
+			return err
+		}
+	}
+	// reset the value, so that nothing is left over from a previous attempt
+	target := reflect.ValueOf(value).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected content after the document")
+	}
+	return nil
+}
+
+func run(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// the editor variable can contain arguments, e.g. "code --wait"
+	command := strings.Fields(editor)
+	cmd := exec.Command(command[0], append(command[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %v", editor, err)
+	}
+	return nil
+}
+
+func commentLines(lines []string) string {
+	var out strings.Builder
+	for _, line := range lines {
+		out.WriteString("# " + line + "\n")
+	}
+	return out.String()
+}
+
+func stripComments(document string) string {
+	lines := []string{}
+	for _, line := range strings.SplitAfter(document, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package editor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDocument struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule"`
+	Flag     string   `json:"flag,omitempty"`
+	Items    []string `json:"items"`
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	document := testDocument{Name: "prod-1", Schedule: "* 9-16 * * 1-5", Flag: "true", Items: []string{"a", "b"}}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   FormatYAML,
+			expected: "name: prod-1\nschedule: '* 9-16 * * 1-5'\nflag: \"true\"\nitems:\n  - a\n  - b\n",
+		},
+		{
+			format:   FormatJSON,
+			expected: "{\n  \"name\": \"prod-1\",\n  \"schedule\": \"* 9-16 * * 1-5\",\n  \"flag\": \"true\",\n  \"items\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			e, err := NewEditor(tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			body, err := e.marshal(document)
+			if err != nil {
+				t.Fatalf("can't marshal: %v", err)
+			}
+			// the fields keep the order of the struct
+			if body != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, body)
+			}
+			var decoded testDocument
+			if err := e.unmarshal(body, &decoded); err != nil {
+				t.Fatalf("can't unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, document) {
+				t.Errorf("expected %+v, got %+v", document, decoded)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		document      string
+		expectedError bool
+	}{
+		{name: "yaml", format: FormatYAML, document: "name: a\nitems: [x]\n"},
+		{name: "yaml unknown field", format: FormatYAML, document: "name: a\nunknown: b\n", expectedError: true},
+		{name: "yaml second document", format: FormatYAML, document: "name: a\n---\nname: b\n", expectedError: true},
+		{name: "yaml syntax", format: FormatYAML, document: "name: [a\n", expectedError: true},
+		{name: "json", format: FormatJSON, document: `{"name": "a", "items": ["x"]}`},
+		{name: "json unknown field", format: FormatJSON, document: `{"name": "a", "unknown": "b"}`, expectedError: true},
+		{name: "json trailing content", format: FormatJSON, document: `{"name": "a"} {"name": "b"}`, expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEditor(tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// a previous attempt must not leak into the result
+			decoded := testDocument{Schedule: "left over"}
+			err = e.unmarshal(tt.document, &decoded)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", decoded)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := testDocument{Name: "a", Items: []string{"x"}}
+			if !reflect.DeepEqual(decoded, expected) {
+				t.Errorf("expected %+v, got %+v", expected, decoded)
+			}
+		})
+	}
+}
+
+func TestNewEditorUnsupportedFormat(t *testing.T) {
+	if _, err := NewEditor("toml"); err == nil {
+		t.Errorf("expected an unsupported format to fail")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff, err := Diff("name: a\nitems:\n  - x\n", "name: b\nitems:\n  - x\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "--- current\n+++ edited\n@@ -1,3 +1,3 @@\n-name: a\n+name: b\n items:\n   - x\n"
+	if diff != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, diff)
+	}
+	if diff, _ := Diff("name: a\n", "name: a\n"); diff != "" {
+		t.Errorf("expected no diff for equal documents, got %q", diff)
+	}
+}
+
+// setEditor points $VISUAL to a shell script that edits the file given as its argument.
+func setEditor(t *testing.T, script string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatalf("can't write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "sh "+path)
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        string
+		expected      testDocument
+		expectedError string
+	}{
+		{
+			name:     "changed",
+			script:   `sed -i 's/name: a/name: b/' "$1"`,
+			expected: testDocument{Name: "b", Items: []string{"x"}},
+		},
+		{
+			name:          "unchanged",
+			script:        "true",
+			expectedError: ErrCancelled.Error(),
+		},
+		{
+			name:          "emptied",
+			script:        `: > "$1"`,
+			expectedError: ErrCancelled.Error(),
+		},
+		{
+			name:          "invalid twice",
+			script:        `sed -i 's/name: a/unknown: a/' "$1"`,
+			expectedError: "edited document is invalid",
+		},
+		{
+			name:          "rejected by validation",
+			script:        `sed -i 's/name: a/name: invalid/' "$1"`,
+			expectedError: "edited document is invalid: name invalid",
+		},
+		{
+			name:          "editor failure",
+			script:        "exit 1",
+			expectedError: "editor sh",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEditor(t, tt.script)
+			e, err := NewEditor(FormatYAML)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var edited testDocument
+			validate := func() error {
+				if edited.Name == "invalid" {
+					return errors.New("name invalid")
+				}
+				return nil
+			}
+			original, result, err := e.Edit("test", []string{"a comment"}, testDocument{Name: "a", Items: []string{"x"}}, &edited, validate)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edited, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, edited)
+			}
+			if original != "name: a\nschedule: \"\"\nitems:\n  - x\n" || result != "name: b\nschedule: \"\"\nitems:\n  - x\n" {
+				t.Errorf("unexpected original %q or result %q", original, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// Environment variables for non-interactive authentication.
+const (
+	envToken        = "OCM_TOKEN"
+	envClientID     = "OCM_CLIENT_ID"
+	envClientSecret = "OCM_CLIENT_SECRET"
+	envURL          = "OCM_URL"
+	envTokenURL     = "OCM_TOKEN_URL"
+)
+
+var urlAliases = map[string]string{
+	"production":  "https://api.openshift.com",
+	"prod":        "https://api.openshift.com",
+	"staging":     "https://api.stage.openshift.com",
+	"stage":       "https://api.stage.openshift.com",
+	"integration": "https://api.integration.openshift.com",
+	"int":         "https://api.integration.openshift.com",
+}
+
+// tokenRenewalWarning is how long before its expiry an access token that can't be renewed
+// triggers a warning, as long running commands would fail midway.
+const tokenRenewalWarning = 10 * time.Minute
+
+// loadConfig returns the OCM configuration and a description of where it came from. Credentials in
+// the environment take precedence over the configuration file, which is either the one given with
+// ConnectionOptions or the one written by 'ocm login'. OCM_URL applies to the configuration file as
+// well.
+func loadConfig() (*config.Config, string, error) {
+	cfg, err := configFromEnvironment()
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg != nil {
+		return cfg, "the environment", nil
+	}
+
+	if connectionOptions.ConfigFile != "" {
+		cfg, err = readConfigFile(connectionOptions.ConfigFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return withEnvironmentURL(cfg), fmt.Sprintf("config file '%s'", connectionOptions.ConfigFile), nil
+	}
+
+	cfg, err = config.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("can't load config file: %v", err)
+	}
+	location, err := config.Location()
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = os.Stat(location)
+	if cfg == nil || os.IsNotExist(err) {
+		return nil, "", fmt.Errorf(
+			"not logged in, run the 'ocm login' command or set the %s or %s and %s environment variables",
+			envToken, envClientID, envClientSecret,
+		)
+	}
+	return withEnvironmentURL(cfg), fmt.Sprintf("config file '%s'", location), nil
+}
+
+// withEnvironmentURL points a configuration file at the API given by OCM_URL, if it is set.
+func withEnvironmentURL(cfg *config.Config) *config.Config {
+	if url := os.Getenv(envURL); url != "" {
+		cfg.URL = ResolveURL(url)
+	}
+	return cfg
+}
+
+func readConfigFile(path string) (*config.Config, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file: %v", err)
+	}
+	cfg := &config.Config{}
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse config file '%s': %v", path, err)
+	}
+	return cfg, nil
+}
+
+// configFromEnvironment returns a configuration built from the OCM_* environment variables or nil
+// if no credentials are set in the environment.
+func configFromEnvironment() (*config.Config, error) {
+	token := os.Getenv(envToken)
+	clientID := os.Getenv(envClientID)
+	clientSecret := os.Getenv(envClientSecret)
+	if token == "" && clientID == "" && clientSecret == "" {
+		return nil, nil
+	}
+	if (clientID == "") != (clientSecret == "") {
+		return nil, fmt.Errorf("both %s and %s need to be set", envClientID, envClientSecret)
+	}
+
+	return configFromCredentials(os.Getenv(envURL), os.Getenv(envTokenURL), token, clientID, clientSecret), nil
+}
+
+// configFromCredentials builds a configuration from a token or client credentials.
+func configFromCredentials(url string, tokenURL string, token string, clientID string, clientSecret string) *config.Config {
+	cfg := &config.Config{
+		URL:          ResolveURL(url),
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = sdk.DefaultTokenURL
+	}
+	if token != "" {
+		claims, ok := parseTokenClaims(token)
+		if ok && (claims.Type == "" || strings.EqualFold(claims.Type, "Bearer")) {
+			cfg.AccessToken = token
+		} else {
+			cfg.RefreshToken = token
+		}
+	}
+	return cfg
+}
+
+// ResolveURL expands the environment aliases production, staging and integration to the URL of
+// their API gateway. An empty URL resolves to the production environment.
+func ResolveURL(url string) string {
+	if url == "" {
+		return sdk.DefaultURL
+	}
+	if resolved, ok := urlAliases[strings.ToLower(url)]; ok {
+		return resolved
+	}
+	return url
+}
+
+// checkConfig verifies that the configuration has credentials or tokens that can still be used and
+// explains what is wrong otherwise.
+func checkConfig(cfg *config.Config, source string) error {
+	if (cfg.ClientID != "" && cfg.ClientSecret != "") || (cfg.User != "" && cfg.Password != "") {
+		return nil
+	}
+	if cfg.AccessToken == "" && cfg.RefreshToken == "" {
+		return fmt.Errorf(
+			"no credentials found in %s, run the 'ocm login' command or set the %s or %s and %s environment variables",
+			source, envToken, envClientID, envClientSecret,
+		)
+	}
+
+	now := time.Now()
+	if cfg.RefreshToken != "" {
+		expiry, ok := tokenExpiry(cfg.RefreshToken)
+		// offline tokens don't expire and encrypted tokens can't be inspected
+		if !ok || expiry.IsZero() || expiry.After(now) {
+			return nil
+		}
+		if cfg.AccessToken == "" {
+			return fmt.Errorf("the refresh token from %s expired at %s, %s", source, formatTime(expiry), renewHint(source))
+		}
+	}
+
+	expiry, ok := tokenExpiry(cfg.AccessToken)
+	if !ok {
+		return fmt.Errorf("the access token from %s is not a valid JWT", source)
+	}
+	if expiry.IsZero() {
+		return nil
+	}
+	if !expiry.After(now.Add(5 * time.Second)) {
+		if cfg.RefreshToken != "" {
+			return fmt.Errorf("the access and refresh tokens from %s are expired, %s", source, renewHint(source))
+		}
+		return fmt.Errorf(
+			"the access token from %s expired at %s and can't be renewed without a refresh token or client credentials, %s",
+			source, formatTime(expiry), renewHint(source),
+		)
+	}
+	if cfg.RefreshToken == "" && expiry.Before(now.Add(tokenRenewalWarning)) {
+		fmt.Fprintf(
+			os.Stderr,
+			"Warning: the access token from %s expires in %s and can't be renewed\n",
+			source, expiry.Sub(now).Round(time.Second),
+		)
+	}
+	return nil
+}
+
+func renewHint(source string) string {
+	if source == "the environment" {
+		return fmt.Sprintf("provide a new %s", envToken)
+	}
+	return "run the 'ocm login' command"
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.RFC3339)
+}
+
+type tokenClaims struct {
+	Type      string `json:"typ"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// parseTokenClaims returns the claims of a JWT without verifying its signature.
+func parseTokenClaims(token string) (*tokenClaims, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+	claims := &tokenClaims{}
+	err = json.Unmarshal(payload, claims)
+	if err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// tokenExpiry returns the expiry time of a JWT, which is zero if the token doesn't expire. ok is
+// false if the token is not a JWT.
+func tokenExpiry(token string) (expiry time.Time, ok bool) {
+	claims, ok := parseTokenClaims(token)
+	if !ok {
+		return time.Time{}, false
+	}
+	if claims.ExpiresAt == 0 {
+		return time.Time{}, true
+	}
+	return time.Unix(claims.ExpiresAt, 0), true
+}
@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// testToken returns an unsigned JWT of the given type that expires at the given time. A zero time
+// gives a token that doesn't expire.
+func testToken(t *testing.T, typ string, expiresAt time.Time) string {
+	t.Helper()
+	values := map[string]interface{}{"typ": typ}
+	if !expiresAt.IsZero() {
+		values["exp"] = expiresAt.Unix()
+	}
+	claims, err := json.Marshal(values)
+	if err != nil {
+		t.Fatalf("can't marshal claims: %v", err)
+	}
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + "."
+}
+
+// setCredentials sets the credential environment variables, empty values unset them.
+func setCredentials(t *testing.T, token string, clientID string, clientSecret string, url string) {
+	t.Helper()
+	t.Setenv(envToken, token)
+	t.Setenv(envClientID, clientID)
+	t.Setenv(envClientSecret, clientSecret)
+	t.Setenv(envURL, url)
+	t.Setenv(envTokenURL, "")
+}
+
+func TestConfigFromEnvironment(t *testing.T) {
+	accessToken := testToken(t, "Bearer", time.Now().Add(time.Hour))
+	offlineToken := testToken(t, "Offline", time.Time{})
+	tests := []struct {
+		name                 string
+		token                string
+		clientID             string
+		clientSecret         string
+		url                  string
+		expectedNil          bool
+		expectedAccessToken  string
+		expectedRefreshToken string
+		expectedURL          string
+		expectedError        string
+	}{
+		{
+			name:        "nothing set",
+			url:         "staging",
+			expectedNil: true,
+		},
+		{
+			name:                "access token only",
+			token:               accessToken,
+			expectedAccessToken: accessToken,
+			expectedURL:         sdk.DefaultURL,
+		},
+		{
+			name:                 "offline token",
+			token:                offlineToken,
+			url:                  "staging",
+			expectedRefreshToken: offlineToken,
+			expectedURL:          "https://api.stage.openshift.com",
+		},
+		{
+			name:         "client credentials only",
+			clientID:     "id",
+			clientSecret: "secret",
+			url:          "https://ocm.example.com",
+			expectedURL:  "https://ocm.example.com",
+		},
+		{
+			name:          "client ID without secret",
+			clientID:      "id",
+			expectedError: "both OCM_CLIENT_ID and OCM_CLIENT_SECRET need to be set",
+		},
+		{
+			name:          "client secret without ID",
+			clientSecret:  "secret",
+			expectedError: "both OCM_CLIENT_ID and OCM_CLIENT_SECRET need to be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCredentials(t, tt.token, tt.clientID, tt.clientSecret, tt.url)
+			cfg, err := configFromEnvironment()
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedNil {
+				if cfg != nil {
+					t.Errorf("expected no configuration, got %+v", cfg)
+				}
+				return
+			}
+			if cfg.AccessToken != tt.expectedAccessToken || cfg.RefreshToken != tt.expectedRefreshToken {
+				t.Errorf("unexpected tokens %q and %q", cfg.AccessToken, cfg.RefreshToken)
+			}
+			if cfg.ClientID != tt.clientID || cfg.ClientSecret != tt.clientSecret {
+				t.Errorf("unexpected client credentials %q and %q", cfg.ClientID, cfg.ClientSecret)
+			}
+			if cfg.URL != tt.expectedURL || cfg.TokenURL != sdk.DefaultTokenURL {
+				t.Errorf("unexpected URLs %q and %q", cfg.URL, cfg.TokenURL)
+			}
+			if err := checkConfig(cfg, "the environment"); err != nil {
+				t.Errorf("expected the configuration to be usable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckConfigExpiredTokens(t *testing.T) {
+	expiry := time.Now().Add(-time.Hour).Truncate(time.Second)
+	expired := testToken(t, "Bearer", expiry)
+	tests := []struct {
+		name          string
+		token         string
+		expectedError string
+	}{
+		{
+			name:          "expired access token",
+			token:         expired,
+			expectedError: "the access token from the environment expired at " + formatTime(expiry) + " and can't be renewed",
+		},
+		{
+			name:          "expired refresh token",
+			token:         testToken(t, "Refresh", expiry),
+			expectedError: "the refresh token from the environment expired at " + formatTime(expiry) + ", provide a new OCM_TOKEN",
+		},
+		{
+			name:          "token that can't be inspected",
+			token:         "not-a-jwt",
+			expectedError: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCredentials(t, tt.token, "", "", "")
+			cfg, err := configFromEnvironment()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = checkConfig(cfg, "the environment")
+			if tt.expectedError == "" {
+				// tokens that can't be inspected are passed on as refresh tokens
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	// client credentials renew an expired access token
+	cfg := configFromCredentials("", "", expired, "id", "secret")
+	if err := checkConfig(cfg, "the environment"); err != nil {
+		t.Errorf("expected client credentials to be usable, got %v", err)
+	}
+}
+
+func TestLoadConfigFileURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ocm.json")
+	cfg := map[string]string{"url": "https://api.openshift.com", "refresh_token": testToken(t, "Offline", time.Time{})}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("can't marshal config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("can't write config: %v", err)
+	}
+	options := DefaultConnectionOptions()
+	options.ConfigFile = path
+	SetConnectionOptions(options)
+	t.Cleanup(func() { SetConnectionOptions(DefaultConnectionOptions()) })
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "", expected: "https://api.openshift.com"},
+		{url: "staging", expected: "https://api.stage.openshift.com"},
+		{url: "https://ocm.example.com", expected: "https://ocm.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			setCredentials(t, "", "", "", tt.url)
+			loaded, source, err := loadConfig()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if loaded.URL != tt.expected || !strings.Contains(source, path) {
+				t.Errorf("expected URL %s from %s, got %s from %s", tt.expected, path, loaded.URL, source)
+			}
+		})
+	}
+}
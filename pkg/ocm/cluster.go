@@ -17,15 +17,16 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
 	"fmt"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	csv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
-func GetClusterByName(organizationId string, clusterName string, connection *sdk.Connection) (*csv1.Cluster, error) {
+func GetClusterByName(ctx context.Context, organizationId string, clusterName string, connection *sdk.Connection) (*csv1.Cluster, error) {
 	searchQuery := fmt.Sprintf("organization.id = '%s' and name = '%s' and state = 'ready'", organizationId, clusterName)
-	clustersResponse, err := connection.ClustersMgmt().V1().Clusters().List().Size(1).Search(searchQuery).Send()
+	clustersResponse, err := connection.ClustersMgmt().V1().Clusters().List().Size(1).Search(searchQuery).SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +37,9 @@ func GetClusterByName(organizationId string, clusterName string, connection *sdk
 	return clusters[0], nil
 }
 
-func ClustersForOrganization(organizationId string, connection *sdk.Connection) (map[string]*csv1.Cluster, error) {
+func ClustersForOrganization(ctx context.Context, organizationId string, connection *sdk.Connection) (map[string]*csv1.Cluster, error) {
 	searchQuery := fmt.Sprintf("organization.id = '%s' and managed = 'true' and state = 'ready'", organizationId)
-	clusters, err := connection.ClustersMgmt().V1().Clusters().List().Size(100).Search(searchQuery).Send()
+	clusters, err := connection.ClustersMgmt().V1().Clusters().List().Size(100).Search(searchQuery).SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Fixture is the seed data of a fake OCM API server. Objects use the JSON representation of the
+// OCM API. Organizations and subscriptions can carry their labels in a `labels` field, either as a
+// key/value map or as a list of label objects.
+type Fixture struct {
+	Account        map[string]interface{}              `json:"account,omitempty"`
+	Organizations  []map[string]interface{}            `json:"organizations,omitempty"`
+	Subscriptions  []map[string]interface{}            `json:"subscriptions,omitempty"`
+	Clusters       []map[string]interface{}            `json:"clusters,omitempty"`
+	VersionGates   []map[string]interface{}            `json:"version_gates,omitempty"`
+	GateAgreements map[string][]map[string]interface{} `json:"gate_agreements,omitempty"`
+}
+
+func LoadFixture(path string) (*Fixture, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	err = json.Unmarshal(data, fixture)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse fixture '%s': %v", path, err)
+	}
+	return fixture, nil
+}
+
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// fixtureLabels returns the labels defined in the `labels` field of a fixture object.
+func fixtureLabels(object map[string]interface{}) (map[string]string, error) {
+	labels := make(map[string]string)
+	switch value := object["labels"].(type) {
+	case nil:
+	case map[string]string:
+		for k, v := range value {
+			labels[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			labels[k] = fmt.Sprint(v)
+		}
+	case []interface{}:
+		for _, item := range value {
+			label, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid label %v", item)
+			}
+			key, ok := label["key"].(string)
+			if !ok {
+				return nil, fmt.Errorf("label %v has no key", item)
+			}
+			labels[key] = fmt.Sprint(label["value"])
+		}
+	default:
+		return nil, fmt.Errorf("invalid labels %v", value)
+	}
+	return labels, nil
+}
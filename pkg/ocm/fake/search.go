@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// condition is a single comparison of a search expression, e.g. `key like 'prefix%'`.
+type condition struct {
+	field    string
+	operator string
+	values   []string
+}
+
+// search is a parsed OCM search expression. Only conjunctions of simple comparisons are supported,
+// which covers the searches done by the CLI.
+type search []condition
+
+var conditionRE = regexp.MustCompile(`(?is)^([a-z_][a-z0-9_.]*)\s+(=|!=|<>|not\s+like|like|ilike|not\s+in|in)\s+(.+)$`)
+
+func parseSearch(expression string) (search, error) {
+	s := search{}
+	for _, part := range splitConjunction(expression) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		match := conditionRE.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported search condition '%s'", part)
+		}
+		operator := strings.Join(strings.Fields(strings.ToLower(match[2])), " ")
+		if operator == "<>" {
+			operator = "!="
+		}
+		values, err := parseValues(strings.TrimSpace(match[3]), operator == "in" || operator == "not in")
+		if err != nil {
+			return nil, fmt.Errorf("invalid search condition '%s': %v", part, err)
+		}
+		s = append(s, condition{field: match[1], operator: operator, values: values})
+	}
+	return s, nil
+}
+
+// splitConjunction splits the expression at every `and` that is not quoted.
+func splitConjunction(expression string) []string {
+	parts := []string{}
+	quoted := false
+	start := 0
+	for i := 0; i < len(expression); i++ {
+		switch {
+		case expression[i] == '\'':
+			quoted = !quoted
+		case !quoted && i+5 <= len(expression) && strings.EqualFold(expression[i:i+5], " and "):
+			parts = append(parts, expression[start:i])
+			start = i + 5
+			i += 4
+		}
+	}
+	return append(parts, expression[start:])
+}
+
+func parseValues(value string, list bool) ([]string, error) {
+	if !list {
+		v, rest, err := parseValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("unexpected '%s'", rest)
+		}
+		return []string{v}, nil
+	}
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return nil, fmt.Errorf("expected a list of values")
+	}
+	values := []string{}
+	rest := strings.TrimSpace(value[1 : len(value)-1])
+	for rest != "" {
+		var v string
+		var err error
+		v, rest, err = parseValue(rest)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		rest = strings.TrimSpace(rest)
+	}
+	return values, nil
+}
+
+// parseValue parses a quoted string or a bare word and returns the remaining input.
+func parseValue(input string) (string, string, error) {
+	if !strings.HasPrefix(input, "'") {
+		end := strings.IndexAny(input, ", )")
+		if end < 0 {
+			end = len(input)
+		}
+		return input[:end], input[end:], nil
+	}
+	var value strings.Builder
+	for i := 1; i < len(input); i++ {
+		if input[i] != '\'' {
+			value.WriteByte(input[i])
+			continue
+		}
+		if i+1 < len(input) && input[i+1] == '\'' {
+			value.WriteByte('\'')
+			i++
+			continue
+		}
+		return value.String(), input[i+1:], nil
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+func (s search) matches(object map[string]interface{}) bool {
+	for _, c := range s {
+		if !c.matches(object) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) matches(object map[string]interface{}) bool {
+	actual, found := lookup(object, c.field)
+	switch c.operator {
+	case "=":
+		return found && actual == c.values[0]
+	case "!=":
+		return !found || actual != c.values[0]
+	case "like":
+		return found && likeRE(c.values[0], false).MatchString(actual)
+	case "ilike":
+		return found && likeRE(c.values[0], true).MatchString(actual)
+	case "not like":
+		return !found || !likeRE(c.values[0], false).MatchString(actual)
+	case "in":
+		return found && slices.Contains(c.values, actual)
+	case "not in":
+		return !found || !slices.Contains(c.values, actual)
+	}
+	return false
+}
+
+// lookup returns the string representation of the value of a dotted field path.
+func lookup(object map[string]interface{}, field string) (string, bool) {
+	var value interface{} = object
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		value, ok = m[name]
+		if !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case nil:
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+func likeRE(pattern string, ignoreCase bool) *regexp.Regexp {
+	var expression strings.Builder
+	if ignoreCase {
+		expression.WriteString("(?i)")
+	}
+	expression.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expression.WriteString(".*")
+		case '_':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
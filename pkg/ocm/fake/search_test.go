@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		expected    search
+		expectedErr bool
+	}{
+		{
+			name:       "empty",
+			expression: "",
+			expected:   search{},
+		},
+		{
+			name:       "like",
+			expression: "key like 'sre-capabilities.aus.%'",
+			expected:   search{{field: "key", operator: "like", values: []string{"sre-capabilities.aus.%"}}},
+		},
+		{
+			name:       "conjunction",
+			expression: "organization.id = 'org1' AND managed = true",
+			expected: search{
+				{field: "organization.id", operator: "=", values: []string{"org1"}},
+				{field: "managed", operator: "=", values: []string{"true"}},
+			},
+		},
+		{
+			name:       "quoted and",
+			expression: "display_name = 'a and b'",
+			expected:   search{{field: "display_name", operator: "=", values: []string{"a and b"}}},
+		},
+		{
+			name:       "escaped quote",
+			expression: "display_name = 'it''s'",
+			expected:   search{{field: "display_name", operator: "=", values: []string{"it's"}}},
+		},
+		{
+			name:       "in",
+			expression: "status in ('Active', 'Reserved')",
+			expected:   search{{field: "status", operator: "in", values: []string{"Active", "Reserved"}}},
+		},
+		{
+			name:       "not like and <>",
+			expression: "key not  like 'a%' and id <> 'b'",
+			expected: search{
+				{field: "key", operator: "not like", values: []string{"a%"}},
+				{field: "id", operator: "!=", values: []string{"b"}},
+			},
+		},
+		{
+			name:        "disjunction",
+			expression:  "id = 'a' or id = 'b'",
+			expectedErr: true,
+		},
+		{
+			name:        "unterminated string",
+			expression:  "id = 'a",
+			expectedErr: true,
+		},
+		{
+			name:        "in without list",
+			expression:  "id in 'a'",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSearch(tt.expression)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(s) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, s)
+			}
+			for i := range s {
+				if s[i].field != tt.expected[i].field || s[i].operator != tt.expected[i].operator ||
+					len(s[i].values) != len(tt.expected[i].values) {
+					t.Fatalf("expected %v, got %v", tt.expected, s)
+				}
+				for j := range s[i].values {
+					if s[i].values[j] != tt.expected[i].values[j] {
+						t.Fatalf("expected %v, got %v", tt.expected, s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSearchMatches(t *testing.T) {
+	o := object{
+		"id":           "sub1",
+		"display_name": "Prod-1",
+		"managed":      true,
+		"count":        float64(3),
+		"organization": object{"id": "org1"},
+	}
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"id = 'sub1'", true},
+		{"id = 'sub2'", false},
+		{"id != 'sub2'", true},
+		{"display_name like 'Prod-%'", true},
+		{"display_name like 'prod-%'", false},
+		{"display_name ilike 'prod-%'", true},
+		{"display_name like 'Prod-_'", true},
+		{"display_name like 'Prod.%'", false},
+		{"display_name not like 'stage-%'", true},
+		{"managed = true", true},
+		{"count = 3", true},
+		{"organization.id = 'org1'", true},
+		{"organization.name = 'org1'", false},
+		{"organization.name != 'org1'", true},
+		{"id in ('sub0', 'sub1')", true},
+		{"id not in ('sub0', 'sub1')", false},
+		{"id = 'sub1' and managed = false", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			s, err := parseSearch(tt.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.matches(o) != tt.expected {
+				t.Errorf("expected %v", tt.expected)
+			}
+		})
+	}
+}
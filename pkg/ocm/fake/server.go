@@ -0,0 +1,573 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	accountsMgmtPrefix = "/api/accounts_mgmt/v1"
+	clustersMgmtPrefix = "/api/clusters_mgmt/v1"
+)
+
+type object = map[string]interface{}
+
+// Server is an in-memory implementation of the parts of the OCM accounts_mgmt and clusters_mgmt
+// APIs that are used by the CLI. It doesn't verify tokens.
+type Server struct {
+	mutex          sync.Mutex
+	account        object
+	organizations  []object
+	subscriptions  []object
+	clusters       []object
+	versionGates   []object
+	gateAgreements map[string][]object
+	labels         []object
+	// writes counts the write requests, the ones in [failFrom, failUntil) fail
+	writes    int
+	failFrom  int
+	failUntil int
+}
+
+func NewServer(fixture *Fixture) (*Server, error) {
+	if fixture == nil {
+		fixture = &Fixture{}
+	}
+	s := &Server{
+		gateAgreements: make(map[string][]object),
+	}
+	for _, org := range fixture.Organizations {
+		org = copyObject(org)
+		labels, err := fixtureLabels(org)
+		if err != nil {
+			return nil, fmt.Errorf("organization %v: %v", org["id"], err)
+		}
+		delete(org, "labels")
+		id := s.ensureID(org)
+		setDefault(org, "kind", "Organization")
+		setDefault(org, "href", fmt.Sprintf("%s/organizations/%s", accountsMgmtPrefix, id))
+		s.organizations = append(s.organizations, org)
+		for key, value := range labels {
+			s.upsertLabel("organization_id", id, key, value)
+		}
+	}
+	for _, subscription := range fixture.Subscriptions {
+		subscription = copyObject(subscription)
+		labels, err := fixtureLabels(subscription)
+		if err != nil {
+			return nil, fmt.Errorf("subscription %v: %v", subscription["id"], err)
+		}
+		delete(subscription, "labels")
+		id := s.ensureID(subscription)
+		setDefault(subscription, "kind", "Subscription")
+		setDefault(subscription, "href", fmt.Sprintf("%s/subscriptions/%s", accountsMgmtPrefix, id))
+		s.subscriptions = append(s.subscriptions, subscription)
+		for key, value := range labels {
+			s.upsertLabel("subscription_id", id, key, value)
+		}
+	}
+	for _, cluster := range fixture.Clusters {
+		cluster = copyObject(cluster)
+		id := s.ensureID(cluster)
+		setDefault(cluster, "kind", "Cluster")
+		setDefault(cluster, "href", fmt.Sprintf("%s/clusters/%s", clustersMgmtPrefix, id))
+		s.clusters = append(s.clusters, cluster)
+	}
+	for _, gate := range fixture.VersionGates {
+		gate = copyObject(gate)
+		id := s.ensureID(gate)
+		setDefault(gate, "kind", "VersionGate")
+		setDefault(gate, "href", fmt.Sprintf("%s/version_gates/%s", clustersMgmtPrefix, id))
+		s.versionGates = append(s.versionGates, gate)
+	}
+	for clusterId, agreements := range fixture.GateAgreements {
+		for _, agreement := range agreements {
+			agreement = copyObject(agreement)
+			id := s.ensureID(agreement)
+			setDefault(agreement, "kind", "VersionGateAgreement")
+			setDefault(agreement, "href", fmt.Sprintf("%s/clusters/%s/gate_agreements/%s", clustersMgmtPrefix, clusterId, id))
+			s.gateAgreements[clusterId] = append(s.gateAgreements[clusterId], agreement)
+		}
+	}
+
+	s.account = copyObject(fixture.Account)
+	if s.account == nil {
+		s.account = object{"id": "fake-account", "username": "fake-user"}
+	}
+	setDefault(s.account, "kind", "Account")
+	if _, ok := s.account["organization"]; !ok && len(s.organizations) > 0 {
+		s.account["organization"] = s.organizations[0]
+	}
+	return s, nil
+}
+
+// Fixture returns the current state of the server as a fixture.
+func (s *Server) Fixture() *Fixture {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fixture := &Fixture{
+		Account:        copyObject(s.account),
+		GateAgreements: make(map[string][]object),
+	}
+	for _, org := range s.organizations {
+		org = copyObject(org)
+		org["labels"] = s.labelValues("organization_id", org["id"].(string))
+		fixture.Organizations = append(fixture.Organizations, org)
+	}
+	for _, subscription := range s.subscriptions {
+		subscription = copyObject(subscription)
+		subscription["labels"] = s.labelValues("subscription_id", subscription["id"].(string))
+		fixture.Subscriptions = append(fixture.Subscriptions, subscription)
+	}
+	fixture.Clusters = append(fixture.Clusters, s.clusters...)
+	fixture.VersionGates = append(fixture.VersionGates, s.versionGates...)
+	for clusterId, agreements := range s.gateAgreements {
+		fixture.GateAgreements[clusterId] = append([]object{}, agreements...)
+	}
+	return fixture
+}
+
+// FailWrites makes count write requests fail with an internal server error, starting with the nth
+// write request from now on. Writes are all requests that aren't GET requests. A negative count
+// fails all writes from the nth on.
+func (s *Server) FailWrites(nth int, count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failFrom = s.writes + nth
+	s.failUntil = s.failFrom + count
+	if count < 0 {
+		s.failUntil = -1
+	}
+}
+
+func (s *Server) failWrite(r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return false
+	}
+	s.writes++
+	return s.failFrom > 0 && s.writes >= s.failFrom && (s.failUntil < 0 || s.writes < s.failUntil)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.failWrite(r) {
+		writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("%s %s: injected failure", r.Method, r.URL.Path))
+		return
+	}
+
+	var status int
+	var body interface{}
+	var err error
+	switch {
+	case strings.HasPrefix(r.URL.Path, accountsMgmtPrefix+"/"):
+		status, body, err = s.serveAccountsMgmt(r, splitPath(strings.TrimPrefix(r.URL.Path, accountsMgmtPrefix)))
+	case strings.HasPrefix(r.URL.Path, clustersMgmtPrefix+"/"):
+		status, body, err = s.serveClustersMgmt(r, splitPath(strings.TrimPrefix(r.URL.Path, clustersMgmtPrefix)))
+	default:
+		status = http.StatusNotFound
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if status >= 400 {
+		writeError(w, r, status, fmt.Sprintf("%s %s: %s", r.Method, r.URL.Path, http.StatusText(status)))
+		return
+	}
+	// the SDK expects a JSON content type even for empty responses
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func (s *Server) serveAccountsMgmt(r *http.Request, path []string) (int, interface{}, error) {
+	switch {
+	case match(r, path, "GET", "current_account"):
+		return http.StatusOK, s.account, nil
+	case match(r, path, "GET", "organizations", "*"):
+		return s.get(s.organizations, path[1])
+	case match(r, path, "GET", "organizations", "*", "labels"):
+		if s.find(s.organizations, path[1]) == nil {
+			return http.StatusNotFound, nil, nil
+		}
+		return s.listLabels(r, "organization_id", path[1])
+	case match(r, path, "POST", "organizations", "*", "labels"):
+		if s.find(s.organizations, path[1]) == nil {
+			return http.StatusNotFound, nil, nil
+		}
+		return s.addLabel(r, "organization_id", path[1])
+	case match(r, path, "GET", "organizations", "*", "labels", "*"):
+		return s.getLabel("organization_id", path[1], path[3])
+	case match(r, path, "DELETE", "organizations", "*", "labels", "*"):
+		return s.deleteLabel("organization_id", path[1], path[3])
+	case match(r, path, "GET", "labels"):
+		return s.listLabels(r, "", "")
+	case match(r, path, "GET", "subscriptions"):
+		return s.listSubscriptions(r)
+	case match(r, path, "GET", "subscriptions", "*"):
+		return s.get(s.subscriptions, path[1])
+	case match(r, path, "GET", "subscriptions", "*", "labels"):
+		if s.find(s.subscriptions, path[1]) == nil {
+			return http.StatusNotFound, nil, nil
+		}
+		return s.listLabels(r, "subscription_id", path[1])
+	case match(r, path, "POST", "subscriptions", "*", "labels"):
+		if s.find(s.subscriptions, path[1]) == nil {
+			return http.StatusNotFound, nil, nil
+		}
+		return s.addLabel(r, "subscription_id", path[1])
+	case match(r, path, "GET", "subscriptions", "*", "labels", "*"):
+		return s.getLabel("subscription_id", path[1], path[3])
+	case match(r, path, "DELETE", "subscriptions", "*", "labels", "*"):
+		return s.deleteLabel("subscription_id", path[1], path[3])
+	}
+	return http.StatusNotFound, nil, nil
+}
+
+func (s *Server) serveClustersMgmt(r *http.Request, path []string) (int, interface{}, error) {
+	switch {
+	case match(r, path, "GET", "clusters"):
+		return s.listClusters(r)
+	case match(r, path, "GET", "clusters", "*"):
+		return s.get(s.clusters, path[1])
+	case match(r, path, "GET", "clusters", "*", "gate_agreements"):
+		if s.find(s.clusters, path[1]) == nil {
+			return http.StatusNotFound, nil, nil
+		}
+		return list(r, "VersionGateAgreementList", s.gateAgreements[path[1]], nil)
+	case match(r, path, "POST", "clusters", "*", "gate_agreements"):
+		return s.addGateAgreement(r, path[1])
+	case match(r, path, "GET", "version_gates"):
+		return list(r, "VersionGateList", s.versionGates, nil)
+	case match(r, path, "GET", "version_gates", "*"):
+		return s.get(s.versionGates, path[1])
+	}
+	return http.StatusNotFound, nil, nil
+}
+
+func (s *Server) listSubscriptions(r *http.Request) (int, interface{}, error) {
+	fetchLabels := r.URL.Query().Get("fetchLabels") == "true"
+	items := []object{}
+	for _, subscription := range s.subscriptions {
+		if fetchLabels {
+			subscription = copyObject(subscription)
+			subscription["labels"] = s.labelsOf("subscription_id", subscription["id"].(string))
+		}
+		items = append(items, subscription)
+	}
+	return list(r, "SubscriptionList", items, nil)
+}
+
+func (s *Server) listClusters(r *http.Request) (int, interface{}, error) {
+	// clusters can be searched by organization, which is a property of their subscription
+	view := func(cluster object) object {
+		for _, subscription := range s.subscriptions {
+			if subscription["cluster_id"] == cluster["id"] {
+				cluster = copyObject(cluster)
+				setDefault(cluster, "organization", object{"id": subscription["organization_id"]})
+				break
+			}
+		}
+		return cluster
+	}
+	return list(r, "ClusterList", s.clusters, view)
+}
+
+func (s *Server) listLabels(r *http.Request, ownerField string, ownerId string) (int, interface{}, error) {
+	items := s.labels
+	if ownerField != "" {
+		items = s.labelsOf(ownerField, ownerId)
+	}
+	return list(r, "LabelList", items, nil)
+}
+
+func (s *Server) getLabel(ownerField string, ownerId string, key string) (int, interface{}, error) {
+	label := s.findLabel(ownerField, ownerId, key)
+	if label == nil {
+		return http.StatusNotFound, nil, nil
+	}
+	return http.StatusOK, label, nil
+}
+
+func (s *Server) addLabel(r *http.Request, ownerField string, ownerId string) (int, interface{}, error) {
+	body := object{}
+	err := readJSON(r, &body)
+	if err != nil {
+		return 0, nil, err
+	}
+	key, ok := body["key"].(string)
+	if !ok || key == "" {
+		return 0, nil, fmt.Errorf("label key is required")
+	}
+	value, ok := body["value"].(string)
+	if !ok {
+		return 0, nil, fmt.Errorf("label value is required")
+	}
+	label, created := s.upsertLabel(ownerField, ownerId, key, value)
+	if created {
+		return http.StatusCreated, label, nil
+	}
+	return http.StatusOK, label, nil
+}
+
+func (s *Server) deleteLabel(ownerField string, ownerId string, key string) (int, interface{}, error) {
+	for i, label := range s.labels {
+		if label[ownerField] == ownerId && label["key"] == key {
+			s.labels = append(s.labels[:i], s.labels[i+1:]...)
+			return http.StatusNoContent, nil, nil
+		}
+	}
+	return http.StatusNotFound, nil, nil
+}
+
+func (s *Server) addGateAgreement(r *http.Request, clusterId string) (int, interface{}, error) {
+	if s.find(s.clusters, clusterId) == nil {
+		return http.StatusNotFound, nil, nil
+	}
+	body := object{}
+	err := readJSON(r, &body)
+	if err != nil {
+		return 0, nil, err
+	}
+	gateRef, ok := body["version_gate"].(object)
+	if !ok {
+		return 0, nil, fmt.Errorf("version gate is required")
+	}
+	gateId, _ := gateRef["id"].(string)
+	gate := s.find(s.versionGates, gateId)
+	if gate == nil {
+		return 0, nil, fmt.Errorf("version gate '%s' not found", gateId)
+	}
+	id := newID()
+	agreement := object{
+		"kind":             "VersionGateAgreement",
+		"id":               id,
+		"href":             fmt.Sprintf("%s/clusters/%s/gate_agreements/%s", clustersMgmtPrefix, clusterId, id),
+		"version_gate":     gate,
+		"agreed_timestamp": now(),
+	}
+	s.gateAgreements[clusterId] = append(s.gateAgreements[clusterId], agreement)
+	return http.StatusCreated, agreement, nil
+}
+
+func (s *Server) upsertLabel(ownerField string, ownerId string, key string, value string) (object, bool) {
+	label := s.findLabel(ownerField, ownerId, key)
+	if label != nil {
+		label["value"] = value
+		label["updated_at"] = now()
+		return label, false
+	}
+	owner := "organizations"
+	if ownerField == "subscription_id" {
+		owner = "subscriptions"
+	}
+	label = object{
+		"kind":       "Label",
+		"id":         newID(),
+		"href":       fmt.Sprintf("%s/%s/%s/labels/%s", accountsMgmtPrefix, owner, ownerId, key),
+		"key":        key,
+		"value":      value,
+		"internal":   false,
+		ownerField:   ownerId,
+		"created_at": now(),
+		"updated_at": now(),
+	}
+	s.labels = append(s.labels, label)
+	return label, true
+}
+
+func (s *Server) findLabel(ownerField string, ownerId string, key string) object {
+	for _, label := range s.labels {
+		if label[ownerField] == ownerId && label["key"] == key {
+			return label
+		}
+	}
+	return nil
+}
+
+func (s *Server) labelsOf(ownerField string, ownerId string) []object {
+	labels := []object{}
+	for _, label := range s.labels {
+		if label[ownerField] == ownerId {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+func (s *Server) labelValues(ownerField string, ownerId string) map[string]string {
+	values := make(map[string]string)
+	for _, label := range s.labelsOf(ownerField, ownerId) {
+		values[label["key"].(string)] = label["value"].(string)
+	}
+	return values
+}
+
+func (s *Server) get(objects []object, id string) (int, interface{}, error) {
+	o := s.find(objects, id)
+	if o == nil {
+		return http.StatusNotFound, nil, nil
+	}
+	return http.StatusOK, o, nil
+}
+
+func (s *Server) find(objects []object, id string) object {
+	for _, o := range objects {
+		if o["id"] == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Server) ensureID(o object) string {
+	id, ok := o["id"].(string)
+	if !ok || id == "" {
+		id = newID()
+		o["id"] = id
+	}
+	return id
+}
+
+// list returns a page of the objects that match the search of the request. If view is given, the
+// search is evaluated against the view of an object instead of the object itself.
+func list(r *http.Request, kind string, objects []object, view func(object) object) (int, interface{}, error) {
+	query := r.URL.Query()
+	s, err := parseSearch(query.Get("search"))
+	if err != nil {
+		return 0, nil, err
+	}
+	page, size := 1, 100
+	if value := query.Get("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return 0, nil, fmt.Errorf("invalid page '%s'", value)
+		}
+	}
+	if value := query.Get("size"); value != "" {
+		size, err = strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return 0, nil, fmt.Errorf("invalid size '%s'", value)
+		}
+	}
+
+	matching := []object{}
+	for _, o := range objects {
+		candidate := o
+		if view != nil {
+			candidate = view(o)
+		}
+		if s.matches(candidate) {
+			matching = append(matching, o)
+		}
+	}
+	items := []object{}
+	start := (page - 1) * size
+	if start < len(matching) {
+		end := min(start+size, len(matching))
+		items = matching[start:end]
+	}
+	return http.StatusOK, object{
+		"kind":  kind,
+		"page":  page,
+		"size":  len(items),
+		"total": len(matching),
+		"items": items,
+	}, nil
+}
+
+func match(r *http.Request, path []string, method string, pattern ...string) bool {
+	if r.Method != method || len(path) != len(pattern) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(object{
+		"kind":         "Error",
+		"id":           strconv.Itoa(status),
+		"href":         fmt.Sprintf("/api/errors/%d", status),
+		"code":         fmt.Sprintf("FAKE-%d", status),
+		"reason":       reason,
+		"operation_id": r.Header.Get("X-Operation-Id"),
+	})
+}
+
+func copyObject(o object) object {
+	if o == nil {
+		return nil
+	}
+	c := make(object, len(o))
+	for k, v := range o {
+		c[k] = v
+	}
+	return c
+}
+
+func setDefault(o object, key string, value interface{}) {
+	if _, ok := o[key]; !ok {
+		o[key] = value
+	}
+}
+
+func newID() string {
+	data := make([]byte, 8)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,325 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s, err := NewServer(&Fixture{
+		Organizations: []map[string]interface{}{
+			{"id": "org1", "labels": map[string]interface{}{"sre-capabilities.aus.sectors": "prod"}},
+		},
+		Subscriptions: []map[string]interface{}{
+			{"id": "sub1", "cluster_id": "cl1", "organization_id": "org1", "labels": map[string]interface{}{
+				"sre-capabilities.aus.schedule": "* * * * *",
+				"env":                           "prod",
+			}},
+			{"id": "sub2", "cluster_id": "cl2", "organization_id": "org2"},
+		},
+		Clusters: []map[string]interface{}{
+			{"id": "cl1", "name": "cluster-1"},
+			{"id": "cl2", "name": "cluster-2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("can't create server: %v", err)
+	}
+	httpServer := httptest.NewServer(s)
+	t.Cleanup(httpServer.Close)
+	return s, httpServer
+}
+
+func do(t *testing.T, method string, u string, body string) (int, map[string]interface{}) {
+	t.Helper()
+	request, err := http.NewRequest(method, u, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("can't create request: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, u, err)
+	}
+	defer response.Body.Close()
+	result := map[string]interface{}{}
+	if response.StatusCode != http.StatusNoContent {
+		err = json.NewDecoder(response.Body).Decode(&result)
+		if err != nil {
+			t.Fatalf("%s %s returned invalid JSON: %v", method, u, err)
+		}
+	}
+	return response.StatusCode, result
+}
+
+func itemValues(t *testing.T, list map[string]interface{}, field string) []string {
+	t.Helper()
+	items, ok := list["items"].([]interface{})
+	if !ok {
+		t.Fatalf("expected a list, got %v", list)
+	}
+	values := []string{}
+	for _, item := range items {
+		values = append(values, item.(map[string]interface{})[field].(string))
+	}
+	return values
+}
+
+func TestLabelCRUD(t *testing.T) {
+	for _, owner := range []string{"organizations/org1", "subscriptions/sub1"} {
+		t.Run(owner, func(t *testing.T) {
+			s, httpServer := newTestServer(t)
+			labels := httpServer.URL + accountsMgmtPrefix + "/" + owner + "/labels"
+
+			status, label := do(t, "POST", labels, `{"key": "sre-capabilities.aus.mutexes", "value": "a"}`)
+			if status != http.StatusCreated || label["value"] != "a" {
+				t.Fatalf("expected the label to be created, got %d %v", status, label)
+			}
+			status, label = do(t, "POST", labels, `{"key": "sre-capabilities.aus.mutexes", "value": "b"}`)
+			if status != http.StatusOK || label["value"] != "b" {
+				t.Fatalf("expected the label to be updated, got %d %v", status, label)
+			}
+			status, label = do(t, "GET", labels+"/sre-capabilities.aus.mutexes", "")
+			if status != http.StatusOK || label["value"] != "b" {
+				t.Fatalf("expected the updated label, got %d %v", status, label)
+			}
+			if len(s.labels) != 4 {
+				t.Fatalf("expected 4 labels, got %d", len(s.labels))
+			}
+
+			status, _ = do(t, "DELETE", labels+"/sre-capabilities.aus.mutexes", "")
+			if status != http.StatusNoContent {
+				t.Fatalf("expected the label to be deleted, got %d", status)
+			}
+			status, _ = do(t, "GET", labels+"/sre-capabilities.aus.mutexes", "")
+			if status != http.StatusNotFound {
+				t.Fatalf("expected the label to be gone, got %d", status)
+			}
+			status, _ = do(t, "DELETE", labels+"/sre-capabilities.aus.mutexes", "")
+			if status != http.StatusNotFound {
+				t.Fatalf("expected a second delete to fail, got %d", status)
+			}
+		})
+	}
+}
+
+func TestLabelErrors(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	prefix := httpServer.URL + accountsMgmtPrefix
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"unknown organization", "POST", "/organizations/nope/labels", `{"key": "k", "value": "v"}`, http.StatusNotFound},
+		{"unknown subscription", "GET", "/subscriptions/nope/labels", "", http.StatusNotFound},
+		{"missing key", "POST", "/organizations/org1/labels", `{"value": "v"}`, http.StatusBadRequest},
+		{"missing value", "POST", "/organizations/org1/labels", `{"key": "k"}`, http.StatusBadRequest},
+		{"invalid body", "POST", "/organizations/org1/labels", `{`, http.StatusBadRequest},
+		{"unsupported method", "PATCH", "/organizations/org1/labels/k", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := do(t, tt.method, prefix+tt.path, tt.body)
+			if status != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, status)
+			}
+			if body["kind"] != "Error" {
+				t.Errorf("expected an error object, got %v", body)
+			}
+		})
+	}
+}
+
+func TestFailWrites(t *testing.T) {
+	tests := []struct {
+		name     string
+		nth      int
+		count    int
+		expected []int
+	}{
+		{
+			name:     "single failure",
+			nth:      2,
+			count:    1,
+			expected: []int{http.StatusOK, http.StatusInternalServerError, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "consecutive failures",
+			nth:      2,
+			count:    2,
+			expected: []int{http.StatusOK, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+		},
+		{
+			name:     "all following writes",
+			nth:      3,
+			count:    -1,
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusInternalServerError, http.StatusInternalServerError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, httpServer := newTestServer(t)
+			labels := httpServer.URL + accountsMgmtPrefix + "/organizations/org1/labels"
+			// writes before the injection don't count
+			do(t, "POST", labels, `{"key": "k", "value": "v"}`)
+			s.FailWrites(tt.nth, tt.count)
+			for i, expected := range tt.expected {
+				// reads neither fail nor count as writes
+				if status, _ := do(t, "GET", labels, ""); status != http.StatusOK {
+					t.Fatalf("expected reads to succeed, got %d", status)
+				}
+				status, _ := do(t, "POST", labels, `{"key": "k", "value": "v"}`)
+				if status != expected {
+					t.Errorf("write %d: expected status %d, got %d", i+1, expected, status)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchLabels(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	tests := []struct {
+		name     string
+		path     string
+		search   string
+		expected []string
+	}{
+		{
+			name:     "organization labels by prefix",
+			path:     "/organizations/org1/labels",
+			search:   "key like 'sre-capabilities.aus.%'",
+			expected: []string{"sre-capabilities.aus.sectors"},
+		},
+		{
+			name:     "subscription labels by prefix",
+			path:     "/subscriptions/sub1/labels",
+			search:   "key like 'sre-capabilities.aus.%'",
+			expected: []string{"sre-capabilities.aus.schedule"},
+		},
+		{
+			name:     "all labels by key",
+			path:     "/labels",
+			search:   "key = 'env'",
+			expected: []string{"env"},
+		},
+		{
+			name:     "no match",
+			path:     "/subscriptions/sub1/labels",
+			search:   "key like 'other.%'",
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := httpServer.URL + accountsMgmtPrefix + tt.path + "?search=" + url.QueryEscape(tt.search)
+			status, list := do(t, "GET", u, "")
+			if status != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", status)
+			}
+			keys := itemValues(t, list, "key")
+			if strings.Join(keys, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+
+	status, _ := do(t, "GET", httpServer.URL+accountsMgmtPrefix+"/labels?search="+url.QueryEscape("key ~ 'a'"), "")
+	if status != http.StatusBadRequest {
+		t.Errorf("expected an unsupported search to fail, got %d", status)
+	}
+}
+
+func TestListSubscriptionsFetchLabels(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	u := httpServer.URL + accountsMgmtPrefix + "/subscriptions?search=" + url.QueryEscape("organization_id = 'org1'")
+
+	_, list := do(t, "GET", u, "")
+	if ids := itemValues(t, list, "id"); len(ids) != 1 || ids[0] != "sub1" {
+		t.Fatalf("expected only sub1, got %v", ids)
+	}
+	if _, ok := list["items"].([]interface{})[0].(map[string]interface{})["labels"]; ok {
+		t.Errorf("expected no labels without fetchLabels")
+	}
+
+	_, list = do(t, "GET", u+"&fetchLabels=true", "")
+	labels, ok := list["items"].([]interface{})[0].(map[string]interface{})["labels"].([]interface{})
+	if !ok || len(labels) != 2 {
+		t.Errorf("expected 2 labels with fetchLabels, got %v", labels)
+	}
+}
+
+func TestListClustersByOrganization(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	u := httpServer.URL + clustersMgmtPrefix + "/clusters?search=" + url.QueryEscape("organization.id = 'org2'")
+	_, list := do(t, "GET", u, "")
+	if names := itemValues(t, list, "name"); len(names) != 1 || names[0] != "cluster-2" {
+		t.Errorf("expected only cluster-2, got %v", names)
+	}
+	if _, ok := list["items"].([]interface{})[0].(map[string]interface{})["organization"]; ok {
+		t.Errorf("expected the organization to be used for the search only")
+	}
+}
+
+func TestListPages(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	tests := []struct {
+		query         string
+		expectedIds   []string
+		expectedTotal float64
+	}{
+		{"page=1&size=1", []string{"cl1"}, 2},
+		{"page=2&size=1", []string{"cl2"}, 2},
+		{"page=3&size=1", []string{}, 2},
+		{"size=0", []string{}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, list := do(t, "GET", httpServer.URL+clustersMgmtPrefix+"/clusters?"+tt.query, "")
+			ids := itemValues(t, list, "id")
+			if strings.Join(ids, ",") != strings.Join(tt.expectedIds, ",") || list["total"] != tt.expectedTotal {
+				t.Errorf("expected %v of %v, got %v of %v", tt.expectedIds, tt.expectedTotal, ids, list["total"])
+			}
+		})
+	}
+}
+
+func TestFixtureRoundTrip(t *testing.T) {
+	s, httpServer := newTestServer(t)
+	do(t, "POST", httpServer.URL+accountsMgmtPrefix+"/subscriptions/sub2/labels", `{"key": "k", "value": "v"}`)
+
+	fixture := s.Fixture()
+	restored, err := NewServer(fixture)
+	if err != nil {
+		t.Fatalf("can't restore server: %v", err)
+	}
+	if len(restored.labels) != len(s.labels) {
+		t.Errorf("expected %d labels, got %d", len(s.labels), len(restored.labels))
+	}
+	if restored.findLabel("subscription_id", "sub2", "k")["value"] != "v" {
+		t.Errorf("expected the added label to be restored")
+	}
+}
@@ -17,13 +17,15 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
+
 	"github.com/app-sre/aus-cli/pkg/output"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	csv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
-func GetVersionGates(connection *sdk.Connection) (map[string][]*csv1.VersionGate, error) {
-	gates, err := connection.ClustersMgmt().V1().VersionGates().List().Send()
+func GetVersionGates(ctx context.Context, connection *sdk.Connection) (map[string][]*csv1.VersionGate, error) {
+	gates, err := connection.ClustersMgmt().V1().VersionGates().List().SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +40,8 @@ func GetVersionGates(connection *sdk.Connection) (map[string][]*csv1.VersionGate
 	return gates_map, nil
 }
 
-func GetVersionGateAgreements(clusterId string, connection *sdk.Connection) (map[string]*csv1.VersionGateAgreement, error) {
-	agreements, err := connection.ClustersMgmt().V1().Clusters().Cluster(clusterId).GateAgreements().List().Send()
+func GetVersionGateAgreements(ctx context.Context, clusterId string, connection *sdk.Connection) (map[string]*csv1.VersionGateAgreement, error) {
+	agreements, err := connection.ClustersMgmt().V1().Clusters().Cluster(clusterId).GateAgreements().List().SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +52,7 @@ func GetVersionGateAgreements(clusterId string, connection *sdk.Connection) (map
 	return agreements_map, nil
 }
 
-func AckVersionGate(cluster *csv1.Cluster, gate *csv1.VersionGate, connection *sdk.Connection, dryRun bool) (*csv1.VersionGateAgreement, error) {
+func AckVersionGate(ctx context.Context, cluster *csv1.Cluster, gate *csv1.VersionGate, connection *sdk.Connection, dryRun bool) (*csv1.VersionGateAgreement, error) {
 	agreement, err := csv1.NewVersionGateAgreement().
 		VersionGate(csv1.NewVersionGate().Copy(gate)).
 		Build()
@@ -65,7 +67,7 @@ func AckVersionGate(cluster *csv1.Cluster, gate *csv1.VersionGate, connection *s
 			GateAgreements().
 			Add().
 			Body(agreement).
-			Send()
+			SendContext(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -17,35 +17,37 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
+
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
-func Whoami(connection *sdk.Connection) (*amv1.Account, error) {
-	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+func Whoami(ctx context.Context, connection *sdk.Connection) (*amv1.Account, error) {
+	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return response.Body(), nil
 }
 
-func CurrentOrganizationId(connection *sdk.Connection) (string, error) {
-	account, err := Whoami(connection)
+func CurrentOrganizationId(ctx context.Context, connection *sdk.Connection) (string, error) {
+	account, err := Whoami(ctx, connection)
 	if err != nil {
 		return "", err
 	}
 	return account.Organization().ID(), nil
 }
 
-func GetOrganization(organizationId string, connection *sdk.Connection) (*amv1.Organization, error) {
+func GetOrganization(ctx context.Context, organizationId string, connection *sdk.Connection) (*amv1.Organization, error) {
 	var err error
 	if organizationId == "" {
-		organizationId, err = CurrentOrganizationId(connection)
+		organizationId, err = CurrentOrganizationId(ctx, connection)
 		if err != nil {
 			return nil, err
 		}
 	}
-	response, err := connection.AccountsMgmt().V1().Organizations().Organization(organizationId).Get().Send()
+	response, err := connection.AccountsMgmt().V1().Organizations().Organization(organizationId).Get().SendContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,244 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// envProfiles overrides the location of the profiles file.
+const envProfiles = "OCM_AUS_PROFILES"
+
+// Profile holds the URL and credentials of an OCM environment. Credentials are either a token,
+// client credentials or an OCM configuration file, e.g. one written by 'ocm login' for that
+// environment. Environment variables in credentials and paths are expanded. Team is the team the
+// user acts for when changing policies.
+type Profile struct {
+	URL          string `json:"url,omitempty"`
+	TokenURL     string `json:"token_url,omitempty"`
+	Token        string `json:"token,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	OCMConfig    string `json:"ocm_config,omitempty"`
+	Team         string `json:"team,omitempty"`
+}
+
+type profilesFile struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// ProfilesLocation returns the path of the profiles file.
+func ProfilesLocation() (string, error) {
+	if location := os.Getenv(envProfiles); location != "" {
+		return location, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ocm-aus", "profiles.json"), nil
+}
+
+func loadProfiles() (map[string]*Profile, string, error) {
+	location, err := ProfilesLocation()
+	if err != nil {
+		return nil, "", err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(location)
+	if os.IsNotExist(err) {
+		return map[string]*Profile{}, location, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("can't read profiles file: %v", err)
+	}
+	file := &profilesFile{}
+	err = json.Unmarshal(data, file)
+	if err != nil {
+		return nil, "", fmt.Errorf("can't parse profiles file '%s': %v", location, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = map[string]*Profile{}
+	}
+	return file.Profiles, location, nil
+}
+
+// ProfileNames returns the names of all defined profiles.
+func ProfileNames() ([]string, error) {
+	profiles, _, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// profileConfig returns the configuration of a profile and a description of where it came from.
+func profileConfig(name string) (*config.Config, string, error) {
+	profiles, location, err := loadProfiles()
+	if err != nil {
+		return nil, "", err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, "", fmt.Errorf("profile '%s' is not defined in '%s'", name, location)
+	}
+	source := fmt.Sprintf("profile '%s'", name)
+
+	if profile.OCMConfig != "" {
+		cfg, err := readConfigFile(expandPath(profile.OCMConfig))
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %v", source, err)
+		}
+		if profile.URL != "" {
+			cfg.URL = ResolveURL(profile.URL)
+		}
+		return cfg, source, nil
+	}
+
+	clientID := os.ExpandEnv(profile.ClientID)
+	clientSecret := os.ExpandEnv(profile.ClientSecret)
+	if (clientID == "") != (clientSecret == "") {
+		return nil, "", fmt.Errorf("%s: both client_id and client_secret need to be set", source)
+	}
+	cfg := configFromCredentials(profile.URL, profile.TokenURL, os.ExpandEnv(profile.Token), clientID, clientSecret)
+	return cfg, source, nil
+}
+
+// expandPath expands environment variables and a leading ~ in a path.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// ConnectionManager holds connections to several OCM environments at once. Connections are created
+// on first use and closed together.
+type ConnectionManager struct {
+	mutex         sync.Mutex
+	connections   map[string]*sdk.Connection
+	organizations map[string]*sdk.Connection
+}
+
+func NewConnectionManager() *ConnectionManager {
+	return &ConnectionManager{
+		connections:   make(map[string]*sdk.Connection),
+		organizations: make(map[string]*sdk.Connection),
+	}
+}
+
+// Default returns the connection selected by the command line, i.e. the one of the --profile flag
+// or the one NewOCMConnection would create.
+func (m *ConnectionManager) Default() (*sdk.Connection, error) {
+	return m.Profile(connectionOptions.Profile)
+}
+
+// Profile returns the connection of the given profile. An empty name refers to the connection
+// configured by the environment or the OCM configuration file.
+func (m *ConnectionManager) Profile(name string) (*sdk.Connection, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if connection, ok := m.connections[name]; ok {
+		return connection, nil
+	}
+	connection, err := newConnection(name)
+	if err != nil {
+		return nil, err
+	}
+	m.connections[name] = connection
+	return connection, nil
+}
+
+// ForOrganization returns a connection to the environment the organization lives in, together with
+// the organization. The default connection is tried first, then the connections of all profiles.
+func (m *ConnectionManager) ForOrganization(ctx context.Context, organizationId string) (*sdk.Connection, *amv1.Organization, error) {
+	m.mutex.Lock()
+	connection, ok := m.organizations[organizationId]
+	m.mutex.Unlock()
+	if ok {
+		organization, err := GetOrganization(ctx, organizationId, connection)
+		return connection, organization, err
+	}
+
+	names, err := ProfileNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	// try the selected profile first
+	candidates := append([]string{connectionOptions.Profile}, names...)
+	tried := map[string]bool{}
+	failures := []string{}
+	for _, name := range candidates {
+		if tried[name] {
+			continue
+		}
+		tried[name] = true
+		connection, err := m.Profile(name)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", profileDescription(name), err))
+			continue
+		}
+		organization, err := GetOrganization(ctx, organizationId, connection)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", profileDescription(name), err))
+			continue
+		}
+		m.mutex.Lock()
+		m.organizations[organizationId] = connection
+		m.mutex.Unlock()
+		return connection, organization, nil
+	}
+	return nil, nil, fmt.Errorf("organization %s not found in any environment (%s)", organizationId, strings.Join(failures, "; "))
+}
+
+func profileDescription(name string) string {
+	if name == "" {
+		return "default connection"
+	}
+	return fmt.Sprintf("profile '%s'", name)
+}
+
+func (m *ConnectionManager) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for name, connection := range m.connections {
+		_ = connection.Close()
+		delete(m.connections, name)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/app-sre/aus-cli/pkg/ocm/fake"
+)
+
+// newOrganizationServer starts a fake OCM API server with a single organization and returns its URL.
+func newOrganizationServer(t *testing.T, organizationId string) string {
+	t.Helper()
+	server, err := fake.NewServer(&fake.Fixture{
+		Organizations: []map[string]interface{}{{"id": organizationId, "name": organizationId}},
+	})
+	if err != nil {
+		t.Fatalf("can't create fake server: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+// writeProfiles writes a profiles file and points OCM_AUS_PROFILES to it.
+func writeProfiles(t *testing.T, profiles map[string]*Profile) {
+	t.Helper()
+	data, err := json.Marshal(profilesFile{Profiles: profiles})
+	if err != nil {
+		t.Fatalf("can't marshal profiles: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("can't write profiles: %v", err)
+	}
+	t.Setenv(envProfiles, path)
+}
+
+func TestProfileConfig(t *testing.T) {
+	token := testToken(t, "Bearer", time.Now().Add(time.Hour))
+	t.Setenv("AUS_TEST_SECRET", "secret")
+	writeProfiles(t, map[string]*Profile{
+		"token":       {URL: "staging", Token: token},
+		"client":      {URL: "https://ocm.example.com", ClientID: "id", ClientSecret: "${AUS_TEST_SECRET}"},
+		"half-client": {ClientID: "id"},
+	})
+
+	tests := []struct {
+		name                 string
+		profile              string
+		expectedURL          string
+		expectedClientSecret string
+		expectedError        string
+	}{
+		{
+			name:        "token",
+			profile:     "token",
+			expectedURL: "https://api.stage.openshift.com",
+		},
+		{
+			name:                 "client credentials from the environment",
+			profile:              "client",
+			expectedURL:          "https://ocm.example.com",
+			expectedClientSecret: "secret",
+		},
+		{
+			name:          "client ID without secret",
+			profile:       "half-client",
+			expectedError: "both client_id and client_secret need to be set",
+		},
+		{
+			name:          "unknown profile",
+			profile:       "unknown",
+			expectedError: "profile 'unknown' is not defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := profileConfig(tt.profile)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.URL != tt.expectedURL || cfg.ClientSecret != tt.expectedClientSecret {
+				t.Errorf("expected URL %q and client secret %q, got %q and %q", tt.expectedURL, tt.expectedClientSecret, cfg.URL, cfg.ClientSecret)
+			}
+		})
+	}
+
+	if _, err := NewConnectionManager().Profile("unknown"); err == nil {
+		t.Errorf("expected the connection of an unknown profile to fail")
+	}
+}
+
+func TestConnectionManagerForOrganization(t *testing.T) {
+	token := testToken(t, "Bearer", time.Now().Add(time.Hour))
+	setCredentials(t, token, "", "", newOrganizationServer(t, "org-default"))
+	writeProfiles(t, map[string]*Profile{
+		"a": {URL: newOrganizationServer(t, "org-a"), Token: token},
+		"b": {URL: newOrganizationServer(t, "org-b"), Token: token},
+	})
+	SetConnectionOptions(DefaultConnectionOptions())
+
+	tests := []struct {
+		organizationId  string
+		expectedProfile string
+		expectedError   string
+	}{
+		{organizationId: "org-a", expectedProfile: "a"},
+		{organizationId: "org-b", expectedProfile: "b"},
+		// organizations of no profile fall back to the default connection
+		{organizationId: "org-default", expectedProfile: ""},
+		{organizationId: "org-unknown", expectedError: "organization org-unknown not found in any environment"},
+	}
+	manager := NewConnectionManager()
+	defer manager.Close()
+	for _, tt := range tests {
+		t.Run(tt.organizationId, func(t *testing.T) {
+			connection, organization, err := manager.ForOrganization(context.Background(), tt.organizationId)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if organization.ID() != tt.organizationId {
+				t.Errorf("expected organization %s, got %s", tt.organizationId, organization.ID())
+			}
+			expected, err := manager.Profile(tt.expectedProfile)
+			if err != nil {
+				t.Fatalf("can't get the connection of the profile: %v", err)
+			}
+			if connection != expected {
+				t.Errorf("expected the connection of %s", profileDescription(tt.expectedProfile))
+			}
+		})
+	}
+}
+
+func TestConnectionManagerReuseAndClose(t *testing.T) {
+	token := testToken(t, "Bearer", time.Now().Add(time.Hour))
+	writeProfiles(t, map[string]*Profile{
+		"a": {URL: newOrganizationServer(t, "org-a"), Token: token},
+	})
+
+	manager := NewConnectionManager()
+	first, err := manager.Profile("a")
+	if err != nil {
+		t.Fatalf("can't create connection: %v", err)
+	}
+	second, err := manager.Profile("a")
+	if err != nil {
+		t.Fatalf("can't get connection: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected the connection of a profile to be reused")
+	}
+
+	manager.Close()
+	if _, err := GetOrganization(context.Background(), "org-a", first); err == nil {
+		t.Errorf("expected the connection to be closed")
+	}
+	third, err := manager.Profile("a")
+	if err != nil {
+		t.Fatalf("can't create connection: %v", err)
+	}
+	defer manager.Close()
+	if third == first {
+		t.Errorf("expected a new connection after closing the manager")
+	}
+}
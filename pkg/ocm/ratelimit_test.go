@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// the first request is sent right away, the other four are spaced by 10ms
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected 5 requests at 100 requests per second to take at least 40ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	for _, requestsPerSecond := range []float64{0, -1} {
+		if limiter := NewRateLimiter(requestsPerSecond); limiter != nil {
+			t.Errorf("expected no limiter for %v requests per second", requestsPerSecond)
+		}
+	}
+	var limiter *RateLimiter
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("expected a nil limiter not to wait, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterCanceledContext(t *testing.T) {
+	limiter := NewRateLimiter(0.1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("expected the canceled context to be reported, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a canceled wait to return right away, took %v", elapsed)
+	}
+	if err := (*RateLimiter)(nil).Wait(ctx); err != context.Canceled {
+		t.Errorf("expected a nil limiter to report the canceled context, got %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveFields are redacted from recorded JSON and form bodies.
+var sensitiveFields = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"client_secret",
+	"password",
+}
+
+// sensitiveHeaders are not recorded at all.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Interaction is a recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// RecordingTransport is an http.RoundTripper that writes every request/response pair as a JSON
+// file into a directory. Tokens, secrets and credentials are redacted.
+type RecordingTransport struct {
+	next  http.RoundTripper
+	dir   string
+	mutex sync.Mutex
+	count int
+}
+
+func NewRecordingTransport(next http.RoundTripper, dir string) *RecordingTransport {
+	return &RecordingTransport{
+		next: next,
+		dir:  dir,
+	}
+}
+
+func RecordingTransportWrapper(dir string) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewRecordingTransport(next, dir)
+	}
+}
+
+func (t *RecordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readBody(&response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: redactHeader(request.Header),
+			Body:   redactBody(requestBody, request.Header.Get("Content-Type")),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     redactHeader(response.Header),
+			Body:       redactBody(responseBody, response.Header.Get("Content-Type")),
+		},
+	}
+	err = t.write(interaction)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (t *RecordingTransport) write(interaction Interaction) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.count == 0 {
+		err := os.MkdirAll(t.dir, 0750)
+		if err != nil {
+			return fmt.Errorf("can't create recording directory: %v", err)
+		}
+	}
+	t.count++
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(t.dir, fmt.Sprintf("%05d.json", t.count))
+	return os.WriteFile(file, data, 0600)
+}
+
+// ReplayTransport is an http.RoundTripper that answers requests from a recording made by a
+// RecordingTransport, without any network access. Requests are matched by method, path, query and
+// body. Identical requests are answered in the order they were recorded, the last answer is
+// repeated once all of them have been used.
+type ReplayTransport struct {
+	mutex        sync.Mutex
+	interactions map[string][]Interaction
+	baseURL      string
+}
+
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded interactions found in '%s'", dir)
+	}
+	sort.Strings(files)
+
+	t := &ReplayTransport{
+		interactions: make(map[string][]Interaction),
+	}
+	for _, file := range files {
+		// #nosec G304
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var interaction Interaction
+		err = json.Unmarshal(data, &interaction)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse recorded interaction '%s': %v", file, err)
+		}
+		parsed, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse URL of recorded interaction '%s': %v", file, err)
+		}
+		if t.baseURL == "" && strings.HasPrefix(parsed.Path, "/api/") {
+			t.baseURL = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+		}
+		key := interactionKey(interaction.Request.Method, parsed, interaction.Request.Body)
+		t.interactions[key] = append(t.interactions[key], interaction)
+	}
+	return t, nil
+}
+
+// BaseURL returns the URL of the API server the recording was made against.
+func (t *ReplayTransport) BaseURL() string {
+	return t.baseURL
+}
+
+func (t *ReplayTransport) Wrap(http.RoundTripper) http.RoundTripper {
+	return t
+}
+
+func (t *ReplayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := interactionKey(request.Method, request.URL, redactBody(body, request.Header.Get("Content-Type")))
+
+	t.mutex.Lock()
+	candidates := t.interactions[key]
+	if len(candidates) == 0 {
+		t.mutex.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s %s", request.Method, request.URL.RequestURI())
+	}
+	interaction := candidates[0]
+	if len(candidates) > 1 {
+		t.interactions[key] = candidates[1:]
+	}
+	t.mutex.Unlock()
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       request,
+	}, nil
+}
+
+func interactionKey(method string, u *url.URL, body string) string {
+	key := fmt.Sprintf("%s %s?%s", method, u.Path, u.Query().Encode())
+	if body != "" {
+		digest := sha256.Sum256([]byte(body))
+		key += " " + hex.EncodeToString(digest[:])
+	}
+	return key
+}
+
+// readBody reads the body fully and replaces it with a copy, so that it can still be consumed.
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	redactedHeader := header.Clone()
+	for _, name := range sensitiveHeaders {
+		redactedHeader.Del(name)
+	}
+	return redactedHeader
+}
+
+func redactBody(body string, contentType string) string {
+	if body == "" {
+		return body
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			return redacted
+		}
+		for _, field := range sensitiveFields {
+			if values.Has(field) {
+				values.Set(field, redacted)
+			}
+		}
+		return values.Encode()
+	}
+	var document interface{}
+	if json.Unmarshal([]byte(body), &document) != nil {
+		return body
+	}
+	if !redactJSON(document) {
+		return body
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return redacted
+	}
+	return string(data)
+}
+
+// redactJSON replaces sensitive fields in the document and reports whether anything was changed.
+func redactJSON(document interface{}) bool {
+	changed := false
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if slices.Contains(sensitiveFields, k) {
+				value[k] = redacted
+				changed = true
+				continue
+			}
+			changed = redactJSON(v) || changed
+		}
+	case []interface{}:
+		for _, v := range value {
+			changed = redactJSON(v) || changed
+		}
+	}
+	return changed
+}
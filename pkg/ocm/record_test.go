@@ -0,0 +1,265 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-token")
+	header.Set("Cookie", "session=secret")
+	header.Set("Accept", "application/json")
+	redactedHeader := redactHeader(header)
+	if redactedHeader.Get("Authorization") != "" || redactedHeader.Get("Cookie") != "" {
+		t.Errorf("expected sensitive headers to be removed, got %v", redactedHeader)
+	}
+	if redactedHeader.Get("Accept") != "application/json" {
+		t.Errorf("expected other headers to be kept, got %v", redactedHeader)
+	}
+	if header.Get("Authorization") == "" {
+		t.Errorf("expected the original header to be unchanged")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		expected    string
+	}{
+		{
+			name:        "JSON tokens",
+			body:        `{"access_token": "a", "refresh_token": "r", "token_type": "Bearer"}`,
+			contentType: "application/json",
+			expected:    `{"access_token":"REDACTED","refresh_token":"REDACTED","token_type":"Bearer"}`,
+		},
+		{
+			name:        "nested JSON",
+			body:        `{"items": [{"password": "p", "id": "1"}]}`,
+			contentType: "application/json",
+			expected:    `{"items":[{"id":"1","password":"REDACTED"}]}`,
+		},
+		{
+			name:        "JSON without secrets is kept as is",
+			body:        `{"id": "1"}`,
+			contentType: "application/json",
+			expected:    `{"id": "1"}`,
+		},
+		{
+			name:        "form",
+			body:        "client_id=aus&client_secret=s&grant_type=password&password=p&username=u",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			expected:    "client_id=aus&client_secret=REDACTED&grant_type=password&password=REDACTED&username=u",
+		},
+		{
+			name:        "text",
+			body:        "not json",
+			contentType: "text/plain",
+			expected:    "not json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if body := redactBody(tt.body, tt.contentType); body != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, body)
+			}
+		})
+	}
+}
+
+// newCountingServer answers every request with a response that depends on the request and counts
+// the requests.
+func newCountingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request", strconv.Itoa(int(n)))
+		if r.URL.Path == "/token" {
+			_, _ = io.WriteString(w, `{"access_token": "secret-access", "refresh_token": "secret-refresh"}`)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"method": "`+r.Method+`", "body": "`+string(body)+`", "n": `+strconv.Itoa(int(n))+`}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+type response struct {
+	status int
+	header string
+	body   string
+}
+
+func do(t *testing.T, client *http.Client, method string, u string, contentType string, body string) (response, error) {
+	t.Helper()
+	request, err := http.NewRequest(method, u, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("can't create request: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer secret-bearer")
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	r, err := client.Do(request)
+	if err != nil {
+		return response{}, err
+	}
+	defer r.Body.Close()
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("can't read body: %v", err)
+	}
+	return response{status: r.StatusCode, header: r.Header.Get("X-Request"), body: string(data)}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server, requests := newCountingServer(t)
+	dir := filepath.Join(t.TempDir(), "recording")
+	type call struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+	}
+	calls := []call{
+		{method: "POST", path: "/token", contentType: "application/x-www-form-urlencoded", body: "grant_type=client_credentials&client_id=aus&client_secret=secret-client"},
+		{method: "GET", path: "/api/accounts_mgmt/v1/labels?search=key+like+%27a%25%27"},
+		// identical requests are answered in the recorded order
+		{method: "GET", path: "/api/accounts_mgmt/v1/current_account"},
+		{method: "GET", path: "/api/accounts_mgmt/v1/current_account"},
+		{method: "POST", path: "/api/accounts_mgmt/v1/subscriptions/s1/labels", contentType: "application/json", body: `{"key":"k","value":"v"}`},
+	}
+
+	recorder := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, dir)}
+	recorded := []response{}
+	for _, c := range calls {
+		r, err := do(t, recorder, c.method, server.URL+c.path, c.contentType, c.body)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", c.method, c.path, err)
+		}
+		recorded = append(recorded, r)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != len(calls) {
+		t.Fatalf("expected %d recorded interactions, got %d (%v)", len(calls), len(files), err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("can't read recording: %v", err)
+		}
+		if strings.Contains(string(data), "secret-") {
+			t.Errorf("expected secrets to be redacted from %s:\n%s", file, data)
+		}
+	}
+
+	replay, err := NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("can't load recording: %v", err)
+	}
+	if replay.BaseURL() != server.URL {
+		t.Errorf("expected base URL %s, got %s", server.URL, replay.BaseURL())
+	}
+	before := requests.Load()
+	replayer := &http.Client{Transport: replay}
+	for i, c := range calls {
+		r, err := do(t, replayer, c.method, server.URL+c.path, c.contentType, c.body)
+		if err != nil {
+			t.Fatalf("replaying %s %s failed: %v", c.method, c.path, err)
+		}
+		// responses are identical apart from the redacted tokens
+		expected := recorded[i]
+		expected.body = redactBody(expected.body, "application/json")
+		if r != expected {
+			t.Errorf("expected the replayed response %+v to match the recorded %+v", r, expected)
+		}
+	}
+	// the last answer is repeated once all recorded ones are used
+	r, err := do(t, replayer, "GET", server.URL+"/api/accounts_mgmt/v1/current_account", "", "")
+	if err != nil || r != recorded[3] {
+		t.Errorf("expected the last recorded answer to be repeated, got %+v %v", r, err)
+	}
+	if requests.Load() != before {
+		t.Errorf("expected the replay to make no requests, got %d", requests.Load()-before)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	server, requests := newCountingServer(t)
+	dir := t.TempDir()
+	recorder := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, dir)}
+	if _, err := do(t, recorder, "GET", server.URL+"/api/accounts_mgmt/v1/current_account", "", ""); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	replay, err := NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("can't load recording: %v", err)
+	}
+	before := requests.Load()
+	replayer := &http.Client{Transport: replay}
+
+	misses := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: "GET", path: "/api/accounts_mgmt/v1/organizations/o1"},
+		{method: "GET", path: "/api/accounts_mgmt/v1/current_account?page=2"},
+		{method: "POST", path: "/api/accounts_mgmt/v1/current_account", body: "{}"},
+	}
+	for _, miss := range misses {
+		_, err := do(t, replayer, miss.method, server.URL+miss.path, "application/json", miss.body)
+		var urlErr *url.Error
+		if err == nil || !errors.As(err, &urlErr) || !strings.Contains(err.Error(), "no recorded response for "+miss.method) {
+			t.Errorf("expected a replay miss for %s %s, got %v", miss.method, miss.path, err)
+		}
+	}
+	if requests.Load() != before {
+		t.Errorf("expected replay misses to make no requests, got %d", requests.Load()-before)
+	}
+}
+
+func TestNewReplayTransportErrors(t *testing.T) {
+	if _, err := NewReplayTransport(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no recorded interactions") {
+		t.Errorf("expected an empty recording to fail, got %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "00001.json"), []byte("{"), 0600); err != nil {
+		t.Fatalf("can't write recording: %v", err)
+	}
+	if _, err := NewReplayTransport(dir); err == nil || !strings.Contains(err.Error(), "00001.json") {
+		t.Errorf("expected an invalid recording to name the file, got %v", err)
+	}
+}
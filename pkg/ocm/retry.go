@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/app-sre/aus-cli/pkg/output"
+)
+
+// RetryOptions control how OCM API requests are retried.
+type RetryOptions struct {
+	// MaxRetries is the maximum number of retries after the initial attempt.
+	MaxRetries int
+	// Timeout limits the duration of a single attempt. Zero means no timeout.
+	Timeout time.Duration
+	// InitialInterval is the backoff before the first retry. It doubles with every retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the backoff and Retry-After delays.
+	MaxInterval time.Duration
+}
+
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxRetries:      5,
+		Timeout:         60 * time.Second,
+		InitialInterval: time.Second,
+		MaxInterval:     time.Minute,
+	}
+}
+
+// RetryTransport is an http.RoundTripper that retries failed requests with exponential backoff.
+// Requests are only retried when it is safe to do so: GET, HEAD, PUT and DELETE requests are
+// idempotent, POST requests to label collections are upserts. Other requests are only retried on
+// 429 and 503 responses, where the server guarantees that the request was not processed.
+type RetryTransport struct {
+	next    http.RoundTripper
+	options RetryOptions
+}
+
+func NewRetryTransport(next http.RoundTripper, options RetryOptions) *RetryTransport {
+	return &RetryTransport{
+		next:    next,
+		options: options,
+	}
+}
+
+// RetryTransportWrapper returns a function that wraps a transport into a RetryTransport, suitable
+// for sdk.ConnectionBuilder.TransportWrapper.
+func RetryTransportWrapper(options RetryOptions) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewRetryTransport(next, options)
+	}
+}
+
+func (t *RetryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// keep a copy of the body so that it can be sent again
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idempotent := isIdempotent(request)
+	for attempt := 0; ; attempt++ {
+		response, err := t.attempt(request, body)
+		if attempt >= t.options.MaxRetries || !t.shouldRetry(request, response, err, idempotent) {
+			if err == nil && attempt > 0 && request.Method == http.MethodDelete && response.StatusCode == http.StatusNotFound {
+				// an earlier attempt might have deleted the resource before failing
+				return deletedResponse(request, response), nil
+			}
+			return response, err
+		}
+
+		delay := t.backoff(attempt, response)
+		if err != nil {
+			output.Debug(false, "Request %s %s failed (%v), retrying in %s\n", request.Method, request.URL.Path, err, delay)
+		} else {
+			output.Debug(false, "Request %s %s failed with status %d, retrying in %s\n", request.Method, request.URL.Path, response.StatusCode, delay)
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *RetryTransport) attempt(request *http.Request, body []byte) (*http.Response, error) {
+	ctx := request.Context()
+	cancel := context.CancelFunc(func() {})
+	if t.options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.options.Timeout)
+	}
+	attempt := request.Clone(ctx)
+	if body != nil {
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		attempt.ContentLength = int64(len(body))
+	}
+	response, err := t.next.RoundTrip(attempt)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the attempt context must stay alive until the body has been consumed
+	response.Body = &cancelingBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+func (t *RetryTransport) shouldRetry(request *http.Request, response *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		// don't retry if the caller gave up
+		if request.Context().Err() != nil || errors.Is(err, context.Canceled) {
+			return false
+		}
+		return idempotent
+	}
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case response.StatusCode >= 500:
+		return idempotent
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt. A Retry-After header takes precedence over
+// the exponential backoff.
+func (t *RetryTransport) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if delay, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+			return min(delay, t.options.MaxInterval)
+		}
+	}
+	delay := t.options.InitialInterval << attempt
+	if delay <= 0 || delay > t.options.MaxInterval {
+		delay = t.options.MaxInterval
+	}
+	// add up to 20% jitter so that concurrent clients don't retry in lockstep
+	// #nosec G404
+	jitter := time.Duration(rand.Float64() * 0.2 * float64(delay))
+	return delay + jitter
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		// labels are identified by their key, adding an existing label updates it
+		return strings.HasSuffix(strings.TrimSuffix(request.URL.Path, "/"), "/labels")
+	}
+	return false
+}
+
+func deletedResponse(request *http.Request, response *http.Response) *http.Response {
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      response.Proto,
+		ProtoMajor: response.ProtoMajor,
+		ProtoMinor: response.ProtoMinor,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    request,
+	}
+}
+
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
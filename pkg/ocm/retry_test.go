@@ -0,0 +1,266 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func testRetryOptions() RetryOptions {
+	return RetryOptions{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+	}
+}
+
+// statusServer answers the requests with the given statuses in order and repeats the last one.
+// It records the bodies of the requests.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mutex sync.Mutex
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(statuses[min(len(bodies), len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		statuses         []int
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		{"get succeeds", "GET", "/clusters", []int{200}, 1, 200},
+		{"get retries 5xx", "GET", "/clusters", []int{500, 502, 200}, 3, 200},
+		{"get retries 429", "GET", "/clusters", []int{429, 200}, 2, 200},
+		{"get gives up", "GET", "/clusters", []int{503}, 4, 503},
+		{"get doesn't retry 4xx", "GET", "/clusters", []int{404}, 1, 404},
+		{"put retries 5xx", "PUT", "/clusters/a", []int{500, 200}, 2, 200},
+		{"post doesn't retry 5xx", "POST", "/clusters", []int{500, 201}, 1, 500},
+		{"post retries 429", "POST", "/clusters", []int{429, 201}, 2, 201},
+		{"post retries 503", "POST", "/clusters", []int{503, 201}, 2, 201},
+		{"label post retries 5xx", "POST", "/subscriptions/a/labels", []int{500, 201}, 2, 201},
+		{"label post with trailing slash retries 5xx", "POST", "/subscriptions/a/labels/", []int{502, 201}, 2, 201},
+		{"label update post doesn't retry 5xx", "POST", "/subscriptions/a/labels/key", []int{500, 200}, 1, 500},
+		{"retried delete maps 404 to 204", "DELETE", "/labels/a", []int{500, 404}, 2, 204},
+		{"delete keeps a first 404", "DELETE", "/labels/a", []int{404}, 1, 404},
+		{"retried get keeps 404", "GET", "/labels/a", []int{500, 404}, 2, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, bodies := statusServer(t, tt.statuses...)
+			client := &http.Client{Transport: NewRetryTransport(http.DefaultTransport, testRetryOptions())}
+			request, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"key": "a"}`))
+			if err != nil {
+				t.Fatalf("can't create request: %v", err)
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_ = response.Body.Close()
+			if response.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, response.StatusCode)
+			}
+			if len(*bodies) != tt.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.expectedAttempts, len(*bodies))
+			}
+			for _, body := range *bodies {
+				if body != `{"key": "a"}` {
+					t.Errorf("expected the body to be sent with every attempt, got %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	tests := []struct {
+		method           string
+		path             string
+		expectedAttempts int
+	}{
+		{"GET", "/clusters", 4},
+		{"DELETE", "/labels/a", 4},
+		{"POST", "/labels", 4},
+		{"POST", "/clusters", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			attempts := 0
+			next := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				attempts++
+				return nil, errors.New("connection reset")
+			})
+			request, _ := http.NewRequest(tt.method, "http://ocm.test"+tt.path, nil)
+			_, err := NewRetryTransport(next, testRetryOptions()).RoundTrip(request)
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+			if attempts != tt.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.expectedAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	options := RetryOptions{InitialInterval: time.Second, MaxInterval: time.Minute}
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		min        time.Duration
+		max        time.Duration
+	}{
+		{"seconds", "5", 0, 5 * time.Second, 5 * time.Second},
+		{"zero", "0", 3, 0, 0},
+		{"capped", "3600", 0, time.Minute, time.Minute},
+		{"date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 0, time.Minute, time.Minute},
+		{"past date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0, 0},
+		{"invalid", "soon", 0, time.Second, 1200 * time.Millisecond},
+		{"missing", "", 2, 4 * time.Second, 4800 * time.Millisecond},
+		{"exponential backoff is capped", "", 10, time.Minute, 72 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				response.Header.Set("Retry-After", tt.retryAfter)
+			}
+			delay := NewRetryTransport(nil, options).backoff(tt.attempt, response)
+			if delay < tt.min || delay > tt.max {
+				t.Errorf("expected a delay between %s and %s, got %s", tt.min, tt.max, delay)
+			}
+		})
+	}
+}
+
+func TestRetryTransportHonorsRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// without the Retry-After header the retry would wait for an hour
+	options := RetryOptions{MaxRetries: 1, InitialInterval: time.Hour, MaxInterval: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	response, err := NewRetryTransport(http.DefaultTransport, options).RoundTrip(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("expected the second attempt to succeed, got status %d after %d attempts", response.StatusCode, attempts)
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// hang until the client gives up on the attempt
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	options := testRetryOptions()
+	options.Timeout = 50 * time.Millisecond
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	response, err := NewRetryTransport(http.DefaultTransport, options).RoundTrip(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("expected the second attempt to succeed, got status %d after %d attempts", response.StatusCode, attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the first attempt to time out, took %s", elapsed)
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		cancel()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	options := RetryOptions{MaxRetries: 5, InitialInterval: time.Hour, MaxInterval: time.Hour}
+	request, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewRetryTransport(http.DefaultTransport, options).RoundTrip(request)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the request to be canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a canceled context to stop the retries")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
@@ -17,40 +17,90 @@ limitations under the License.
 package ocm
 
 import (
+	"context"
 	"fmt"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
-func SubscriptionsForOrganization(organizationId string, searchQuery string, connection *sdk.Connection) (map[string]*amv1.Subscription, error) {
+func SubscriptionsForOrganization(ctx context.Context, organizationId string, searchQuery string, connection *sdk.Connection) (map[string]*amv1.Subscription, error) {
 	if searchQuery == "" {
 		searchQuery = fmt.Sprintf("organization_id = '%s' and managed = true and status in ('Active', 'Reserved')", organizationId)
 	} else {
 		searchQuery = fmt.Sprintf("organization_id = '%s' and %s", organizationId, searchQuery)
 	}
-	subscriptions, err := connection.AccountsMgmt().V1().Subscriptions().List().Parameter("fetchLabels", "true").Size(100).Search(searchQuery).Send()
-	if err != nil {
-		return nil, err
-	}
 	subscriptionMap := make(map[string]*amv1.Subscription)
-	for _, subscription := range subscriptions.Items().Slice() {
-		subscriptionMap[subscription.ID()] = subscription
+	size := 100
+	for page := 1; ; page++ {
+		subscriptions, err := connection.AccountsMgmt().V1().Subscriptions().List().Parameter("fetchLabels", "true").Page(page).Size(size).Search(searchQuery).SendContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, subscription := range subscriptions.Items().Slice() {
+			subscriptionMap[subscription.ID()] = subscription
+		}
+		if subscriptions.Items().Len() < size {
+			break
+		}
 	}
 	return subscriptionMap, nil
 }
 
-func SubscriptionForDisplayName(organizationId string, displayName string, connection *sdk.Connection) (*amv1.Subscription, error) {
+func SubscriptionForDisplayName(ctx context.Context, organizationId string, displayName string, connection *sdk.Connection) (*amv1.Subscription, error) {
 	searchQuery := fmt.Sprintf("managed = true and status in ('Active', 'Reserved') and display_name = '%s'", displayName)
-	subscriptions, err := SubscriptionsForOrganization(organizationId, searchQuery, connection)
+	subscriptions, err := SubscriptionsForOrganization(ctx, organizationId, searchQuery, connection)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*amv1.Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		matches = append(matches, subscription)
+	}
+	return singleSubscription(organizationId, displayName, matches)
+}
+
+// SubscriptionIndex holds all active subscriptions of an organization, fetched with a single
+// listing, and allows to look them up by display name.
+type SubscriptionIndex struct {
+	organizationId string
+	byDisplayName  map[string][]*amv1.Subscription
+}
+
+func NewSubscriptionIndex(ctx context.Context, organizationId string, connection *sdk.Connection) (*SubscriptionIndex, error) {
+	subscriptions, err := SubscriptionsForOrganization(ctx, organizationId, "", connection)
 	if err != nil {
 		return nil, err
 	}
+	index := &SubscriptionIndex{
+		organizationId: organizationId,
+		byDisplayName:  make(map[string][]*amv1.Subscription),
+	}
+	for _, subscription := range subscriptions {
+		index.byDisplayName[subscription.DisplayName()] = append(index.byDisplayName[subscription.DisplayName()], subscription)
+	}
+	return index, nil
+}
+
+func (i *SubscriptionIndex) ForDisplayName(displayName string) (*amv1.Subscription, error) {
+	return singleSubscription(i.organizationId, displayName, i.byDisplayName[displayName])
+}
+
+// Subscriptions returns all subscriptions of the index.
+func (i *SubscriptionIndex) Subscriptions() []*amv1.Subscription {
+	all := []*amv1.Subscription{}
+	for _, subscriptions := range i.byDisplayName {
+		all = append(all, subscriptions...)
+	}
+	return all
+}
+
+func singleSubscription(organizationId string, displayName string, subscriptions []*amv1.Subscription) (*amv1.Subscription, error) {
 	if len(subscriptions) > 1 {
 		return nil, fmt.Errorf("more than one subscription found for display name '%s' in organization '%s'", displayName, organizationId)
 	}
-	for _, subscription := range subscriptions {
-		return subscription, nil
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("no subscription found for display name '%s' in organization '%s'", displayName, organizationId)
 	}
-	return nil, fmt.Errorf("no subscription found for display name '%s' in organization '%s'", displayName, organizationId)
+	return subscriptions[0], nil
 }
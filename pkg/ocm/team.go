@@ -0,0 +1,40 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import "os"
+
+// envTeam overrides the team of the caller.
+const envTeam = "OCM_AUS_TEAM"
+
+// CallerTeam returns the team the caller acts for. It is taken from the OCM_AUS_TEAM environment
+// variable or the team of the selected profile. It is empty if neither is set.
+func CallerTeam() (string, error) {
+	if team := os.Getenv(envTeam); team != "" {
+		return team, nil
+	}
+	if connectionOptions.Profile != "" {
+		profiles, _, err := loadProfiles()
+		if err != nil {
+			return "", err
+		}
+		if profile, ok := profiles[connectionOptions.Profile]; ok && profile.Team != "" {
+			return profile.Team, nil
+		}
+	}
+	return "", nil
+}
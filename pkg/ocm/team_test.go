@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCallerTeam(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	profiles := `{"profiles": {"with-team": {"url": "http://localhost", "team": "team-a"}, "without-team": {"url": "http://localhost"}}}`
+	if err := os.WriteFile(path, []byte(profiles), 0600); err != nil {
+		t.Fatalf("can't write profiles: %v", err)
+	}
+	t.Setenv(envProfiles, path)
+	t.Cleanup(func() { SetConnectionOptions(DefaultConnectionOptions()) })
+
+	tests := []struct {
+		name     string
+		env      string
+		profile  string
+		expected string
+	}{
+		{"nothing configured", "", "", ""},
+		{"environment", "team-env", "", "team-env"},
+		{"profile", "", "with-team", "team-a"},
+		{"environment overrides profile", "team-env", "with-team", "team-env"},
+		{"profile without team", "", "without-team", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envTeam, tt.env)
+			options := DefaultConnectionOptions()
+			options.Profile = tt.profile
+			SetConnectionOptions(options)
+			team, err := CallerTeam()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if team != tt.expected {
+				t.Errorf("expected team %q, got %q", tt.expected, team)
+			}
+		})
+	}
+}
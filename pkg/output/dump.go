@@ -31,7 +31,7 @@ func PrettyList(stream io.Writer, body []byte) error {
 	if len(body) == 0 {
 		return nil
 	}
-	data := make([]interface{}, 0)
+	var data interface{}
 	err := json.Unmarshal(body, &data)
 	if err != nil {
 		return dumpBytes(stream, body)
@@ -0,0 +1,47 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+// ApplyOptions control how a list of policies is applied.
+type ApplyOptions struct {
+	Dump   bool
+	DryRun bool
+	// Atomic applies all policies as a unit and rolls back all of them if one fails.
+	Atomic bool
+	// Concurrency is the number of policies applied in parallel.
+	Concurrency int
+	// RequestsPerSecond limits the rate of write requests. Zero means no limit.
+	RequestsPerSecond float64
+	// OwnerGuard refuses to change policies owned by other teams.
+	OwnerGuard OwnerGuard
+}
+
+// ApplyResult is the outcome of applying a single policy.
+type ApplyResult struct {
+	ClusterName string
+	Err         error
+}
+
+func FailedApplyResults(results []ApplyResult) []ApplyResult {
+	failed := []ApplyResult{}
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Approval records who approved a version for a cluster or for all clusters of a sector. Clusters
+// with the require_approval condition are only upgraded to approved versions.
+type Approval struct {
+	Version string `json:"version"`
+	// Sector is set for approvals that cover all clusters of a sector.
+	Sector   string    `json:"sector,omitempty"`
+	Approver string    `json:"approver"`
+	Time     time.Time `json:"time"`
+}
+
+func NewApproval(version string, sector string, approver string, now time.Time) Approval {
+	return Approval{
+		Version:  version,
+		Sector:   sector,
+		Approver: approver,
+		Time:     now.UTC().Truncate(time.Second),
+	}
+}
+
+// String returns the approver and the time of the approval, the way they are stored.
+func (a Approval) String() string {
+	return fmt.Sprintf("%s,%s", a.Approver, a.Time.Format(time.RFC3339))
+}
+
+// ParseApproval reads an approval of a version in the format written by String.
+func ParseApproval(version string, sector string, value string) (Approval, error) {
+	i := strings.LastIndex(value, ",")
+	if i < 0 {
+		return Approval{}, fmt.Errorf("invalid approval of version %s: %s", version, value)
+	}
+	approvalTime, err := time.Parse(time.RFC3339, value[i+1:])
+	if err != nil {
+		return Approval{}, fmt.Errorf("invalid approval of version %s: %v", version, err)
+	}
+	return NewApproval(version, sector, value[:i], approvalTime), nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApprovalRoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 30, 15, 500, time.FixedZone("CET", 3600))
+	approval := NewApproval("4.15.2", "prod", "jane,doe", now)
+	if !approval.Time.Equal(time.Date(2026, 3, 4, 9, 30, 15, 0, time.UTC)) || approval.Time.Location() != time.UTC {
+		t.Errorf("expected the approval time to be truncated to UTC seconds, got %v", approval.Time)
+	}
+	if approval.String() != "jane,doe,2026-03-04T09:30:15Z" {
+		t.Errorf("unexpected approval value %q", approval.String())
+	}
+	parsed, err := ParseApproval("4.15.2", "prod", approval.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed != approval {
+		t.Errorf("expected %+v, got %+v", approval, parsed)
+	}
+}
+
+func TestParseApproval(t *testing.T) {
+	tests := []struct {
+		name             string
+		value            string
+		expectedApprover string
+		expectedError    bool
+	}{
+		{
+			name:             "valid",
+			value:            "someone,2026-01-01T00:00:00Z",
+			expectedApprover: "someone",
+		},
+		{
+			name:             "time zone offset",
+			value:            "someone,2026-01-01T01:00:00+01:00",
+			expectedApprover: "someone",
+		},
+		{
+			name:             "empty approver",
+			value:            ",2026-01-01T00:00:00Z",
+			expectedApprover: "",
+		},
+		{
+			name:          "missing time",
+			value:         "someone",
+			expectedError: true,
+		},
+		{
+			name:          "invalid time",
+			value:         "someone,yesterday",
+			expectedError: true,
+		},
+		{
+			name:          "empty",
+			value:         "",
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approval, err := ParseApproval("4.14.1", "", tt.value)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", approval)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if approval.Approver != tt.expectedApprover || approval.Version != "4.14.1" {
+				t.Errorf("unexpected approval %+v", approval)
+			}
+			if !approval.Time.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected approval time %v", approval.Time)
+			}
+		})
+	}
+}
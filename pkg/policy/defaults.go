@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Names of the policy fields that can be inherited from the organization defaults.
+const (
+	ScheduleField = "schedule"
+	SoakDaysField = "soak_days"
+	SectorField   = "sector"
+	MutexesField  = "mutexes"
+)
+
+var DefaultableFields = []string{
+	ScheduleField,
+	SoakDaysField,
+	SectorField,
+	MutexesField,
+}
+
+// PolicyDefaults are organization wide values for policies that don't set them.
+type PolicyDefaults struct {
+	Schedule string   `json:"schedule,omitempty"`
+	SoakDays *int     `json:"soak_days,omitempty"`
+	Sector   string   `json:"sector,omitempty"`
+	Mutexes  []string `json:"mutexes,omitempty"`
+}
+
+func (d PolicyDefaults) Validate() error {
+	if d.SoakDays != nil && *d.SoakDays < 0 {
+		return fmt.Errorf("soak-days must be >= 0")
+	}
+	return nil
+}
+
+func (d PolicyDefaults) Empty() bool {
+	return d.Schedule == "" && d.SoakDays == nil && d.Sector == "" && len(d.Mutexes) == 0
+}
+
+// Merge fills the values the policy doesn't set with the defaults and records them as inherited.
+func (d PolicyDefaults) Merge(p ClusterUpgradePolicy) ClusterUpgradePolicy {
+	merged := p
+	merged.Inherited = []string{}
+	if p.Schedule == "" && d.Schedule != "" {
+		merged.Schedule = d.Schedule
+		merged.Inherited = append(merged.Inherited, ScheduleField)
+	}
+	if p.Conditions.SoakDays == nil && d.SoakDays != nil {
+		soakDays := *d.SoakDays
+		merged.Conditions.SoakDays = &soakDays
+		merged.Inherited = append(merged.Inherited, SoakDaysField)
+	}
+	if p.Conditions.Sector == "" && d.Sector != "" {
+		merged.Conditions.Sector = d.Sector
+		merged.Inherited = append(merged.Inherited, SectorField)
+	}
+	if len(p.Conditions.Mutexes) == 0 && len(d.Mutexes) > 0 {
+		merged.Conditions.Mutexes = d.Mutexes
+		merged.Inherited = append(merged.Inherited, MutexesField)
+	}
+	return merged
+}
+
+// WithoutInherited returns the policy with the inherited values removed, i.e. the values that are
+// set on the cluster itself.
+func (p ClusterUpgradePolicy) WithoutInherited() ClusterUpgradePolicy {
+	explicit := p
+	explicit.Inherited = nil
+	if p.IsInherited(ScheduleField) {
+		explicit.Schedule = ""
+	}
+	if p.IsInherited(SoakDaysField) {
+		explicit.Conditions.SoakDays = nil
+	}
+	if p.IsInherited(SectorField) {
+		explicit.Conditions.Sector = ""
+	}
+	if p.IsInherited(MutexesField) {
+		explicit.Conditions.Mutexes = nil
+	}
+	return explicit
+}
+
+// Explicit removes the inherited values from a policy like WithoutInherited, but only if they still
+// equal the defaults. A value listed as inherited that differs from the default was changed, e.g. in
+// the output of 'get policies', and is reported instead of being replaced by the default.
+func (d PolicyDefaults) Explicit(p ClusterUpgradePolicy) (ClusterUpgradePolicy, error) {
+	for _, field := range p.Inherited {
+		var changed bool
+		switch field {
+		case ScheduleField:
+			changed = p.Schedule != "" && p.Schedule != d.Schedule
+		case SoakDaysField:
+			changed = p.Conditions.SoakDays != nil && (d.SoakDays == nil || *p.Conditions.SoakDays != *d.SoakDays)
+		case SectorField:
+			changed = p.Conditions.Sector != "" && p.Conditions.Sector != d.Sector
+		case MutexesField:
+			changed = len(p.Conditions.Mutexes) > 0 && !slices.Equal(p.Conditions.Mutexes, d.Mutexes)
+		default:
+			return ClusterUpgradePolicy{}, fmt.Errorf("unknown inherited field %s", field)
+		}
+		if changed {
+			return ClusterUpgradePolicy{}, fmt.Errorf("%s is listed as inherited but differs from the organization default, "+
+				"remove it from inherited to set it on the cluster", field)
+		}
+	}
+	return p.WithoutInherited(), nil
+}
+
+func NewPolicyDefaultsFromReader(reader io.Reader) (PolicyDefaults, error) {
+	var defaults PolicyDefaults
+	err := json.NewDecoder(reader).Decode(&defaults)
+	if err != nil {
+		return defaults, err
+	}
+	return defaults, defaults.Validate()
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPolicyDefaultsMerge(t *testing.T) {
+	defaults := PolicyDefaults{
+		Schedule: "0 10 * * 1-5",
+		SoakDays: intPtr(3),
+		Sector:   "prod",
+		Mutexes:  []string{"network"},
+	}
+	tests := []struct {
+		name              string
+		policy            ClusterUpgradePolicy
+		expectedSchedule  string
+		expectedSoakDays  int
+		expectedInherited []string
+	}{
+		{
+			name:              "empty policy inherits everything",
+			policy:            ClusterUpgradePolicy{},
+			expectedSchedule:  "0 10 * * 1-5",
+			expectedSoakDays:  3,
+			expectedInherited: []string{ScheduleField, SoakDaysField, SectorField, MutexesField},
+		},
+		{
+			name: "explicit values are kept",
+			policy: ClusterUpgradePolicy{
+				Schedule: "0 0 * * *",
+				Conditions: ClusterUpgradePolicyConditions{
+					SoakDays: intPtr(0),
+					Sector:   "stage",
+					Mutexes:  []string{"db"},
+				},
+			},
+			expectedSchedule:  "0 0 * * *",
+			expectedSoakDays:  0,
+			expectedInherited: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := defaults.Merge(tt.policy)
+			if merged.Schedule != tt.expectedSchedule {
+				t.Errorf("expected schedule %q, got %q", tt.expectedSchedule, merged.Schedule)
+			}
+			if merged.Conditions.SoakDays == nil || *merged.Conditions.SoakDays != tt.expectedSoakDays {
+				t.Errorf("expected soak days %d, got %v", tt.expectedSoakDays, merged.Conditions.SoakDays)
+			}
+			if !reflect.DeepEqual(merged.Inherited, tt.expectedInherited) {
+				t.Errorf("expected inherited %v, got %v", tt.expectedInherited, merged.Inherited)
+			}
+			if !reflect.DeepEqual(merged.WithoutInherited(), withoutInheritedList(tt.policy)) {
+				t.Errorf("expected WithoutInherited to restore %+v, got %+v", tt.policy, merged.WithoutInherited())
+			}
+		})
+	}
+}
+
+func withoutInheritedList(p ClusterUpgradePolicy) ClusterUpgradePolicy {
+	p.Inherited = nil
+	return p
+}
+
+func TestPolicyDefaultsExplicit(t *testing.T) {
+	defaults := PolicyDefaults{
+		Schedule: "0 10 * * 1-5",
+		SoakDays: intPtr(3),
+		Sector:   "prod",
+		Mutexes:  []string{"network"},
+	}
+	tests := []struct {
+		name        string
+		policy      ClusterUpgradePolicy
+		expected    ClusterUpgradePolicy
+		expectedErr bool
+	}{
+		{
+			name:     "unchanged inherited values are removed",
+			policy:   defaults.Merge(ClusterUpgradePolicy{ClusterName: "c1"}),
+			expected: ClusterUpgradePolicy{ClusterName: "c1"},
+		},
+		{
+			name: "removed inherited values are fine",
+			policy: ClusterUpgradePolicy{
+				ClusterName: "c1",
+				Inherited:   []string{ScheduleField, SoakDaysField, SectorField, MutexesField},
+			},
+			expected: ClusterUpgradePolicy{ClusterName: "c1"},
+		},
+		{
+			name: "explicit values are kept",
+			policy: ClusterUpgradePolicy{
+				ClusterName: "c1",
+				Schedule:    "0 0 * * *",
+				Conditions: ClusterUpgradePolicyConditions{
+					SoakDays: intPtr(3),
+					Sector:   "prod",
+				},
+				Inherited: []string{MutexesField},
+			},
+			expected: ClusterUpgradePolicy{
+				ClusterName: "c1",
+				Schedule:    "0 0 * * *",
+				Conditions: ClusterUpgradePolicyConditions{
+					SoakDays: intPtr(3),
+					Sector:   "prod",
+				},
+			},
+		},
+		{
+			name: "changed inherited schedule",
+			policy: ClusterUpgradePolicy{
+				Schedule:  "0 0 * * *",
+				Inherited: []string{ScheduleField},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "changed inherited soak days",
+			policy: ClusterUpgradePolicy{
+				Conditions: ClusterUpgradePolicyConditions{SoakDays: intPtr(5)},
+				Inherited:  []string{SoakDaysField},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "changed inherited sector",
+			policy: ClusterUpgradePolicy{
+				Conditions: ClusterUpgradePolicyConditions{Sector: "stage"},
+				Inherited:  []string{SectorField},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "changed inherited mutexes",
+			policy: ClusterUpgradePolicy{
+				Conditions: ClusterUpgradePolicyConditions{Mutexes: []string{"network", "db"}},
+				Inherited:  []string{MutexesField},
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown inherited field",
+			policy:      ClusterUpgradePolicy{Inherited: []string{"workloads"}},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explicit, err := defaults.Explicit(tt.policy)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", explicit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(explicit, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, explicit)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import "fmt"
+
+// OwnerGuard protects the policies of a team from being changed by other teams.
+type OwnerGuard struct {
+	// Team is the team of the caller.
+	Team string
+	// Force allows to change policies owned by other teams.
+	Force bool
+}
+
+// Check returns an error if the caller can't change the policy of a cluster owned by owner.
+// Policies without an owner can be changed by everyone, owned policies only by callers with a
+// known team.
+func (g OwnerGuard) Check(clusterName string, owner string) error {
+	if g.Force || owner == "" || owner == g.Team {
+		return nil
+	}
+	if g.Team == "" {
+		return fmt.Errorf("the policy of cluster %s is owned by team %s, but your team is unknown "+
+			"(set OCM_AUS_TEAM or the team of the profile, or use --force-owner to change it anyway)", clusterName, owner)
+	}
+	return fmt.Errorf("the policy of cluster %s is owned by team %s, not by %s (use --force-owner to change it anyway)", clusterName, owner, g.Team)
+}
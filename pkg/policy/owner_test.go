@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnerGuardCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		guard         OwnerGuard
+		owner         string
+		expectedError string
+	}{
+		{"no owner", OwnerGuard{Team: "team-a"}, "", ""},
+		{"no owner and no team", OwnerGuard{}, "", ""},
+		{"own policy", OwnerGuard{Team: "team-a"}, "team-a", ""},
+		{"other team", OwnerGuard{Team: "team-a"}, "team-b", "owned by team team-b, not by team-a"},
+		{"unknown team", OwnerGuard{}, "team-b", "set OCM_AUS_TEAM"},
+		{"forced", OwnerGuard{Team: "team-a", Force: true}, "team-b", ""},
+		{"forced without team", OwnerGuard{Force: true}, "team-b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.guard.Check("cluster-1", tt.owner)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", tt.expectedError, err)
+			}
+			if !strings.Contains(err.Error(), "--force-owner") {
+				t.Errorf("expected the error to mention --force-owner, got %v", err)
+			}
+		})
+	}
+}
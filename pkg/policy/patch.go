@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PolicyPatch is a JSON merge patch (RFC 7386) for the policies of the clusters selected by the
+// cluster name or the selector.
+type PolicyPatch struct {
+	ClusterName string
+	Selector    map[string]string
+	Patch       map[string]interface{}
+}
+
+// NewPolicyPatchesFromReader reads a merge patch document or a list of them. The name and selector
+// fields of a document select the clusters to patch and are not part of the patch.
+func NewPolicyPatchesFromReader(reader io.Reader) ([]PolicyPatch, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var documents []map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		err = json.Unmarshal(body, &documents)
+	} else {
+		var document map[string]interface{}
+		err = json.Unmarshal(body, &document)
+		documents = append(documents, document)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patches := []PolicyPatch{}
+	for _, document := range documents {
+		patch := PolicyPatch{Patch: document}
+		if name, ok := document["name"]; ok {
+			patch.ClusterName, ok = name.(string)
+			if !ok {
+				return nil, fmt.Errorf("name of a patch must be a string")
+			}
+			delete(document, "name")
+		}
+		if selector, ok := document["selector"]; ok {
+			patch.Selector = map[string]string{}
+			selectorMap, ok := selector.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("selector of a patch must be an object")
+			}
+			for key, value := range selectorMap {
+				patch.Selector[key] = fmt.Sprint(value)
+			}
+			delete(document, "selector")
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// MergePatch applies a JSON merge patch to the policy. The cluster name and selector of the policy
+// are kept.
+func (p ClusterUpgradePolicy) MergePatch(patch map[string]interface{}) (ClusterUpgradePolicy, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return p, err
+	}
+	var target interface{}
+	err = json.Unmarshal(body, &target)
+	if err != nil {
+		return p, err
+	}
+	body, err = json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return p, err
+	}
+	var patched ClusterUpgradePolicy
+	err = json.Unmarshal(body, &patched)
+	if err != nil {
+		return p, fmt.Errorf("invalid patch for cluster %s: %v", p.ClusterName, err)
+	}
+	patched.ClusterName = p.ClusterName
+	patched.Selector = p.Selector
+	return patched, nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewPolicyPatchesFromReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []PolicyPatch
+		expectedErr bool
+	}{
+		{
+			name:  "single document",
+			input: `{"name": "prod-1", "schedule": "0 0 * * *"}`,
+			expected: []PolicyPatch{
+				{ClusterName: "prod-1", Patch: map[string]interface{}{"schedule": "0 0 * * *"}},
+			},
+		},
+		{
+			name:  "list of documents",
+			input: ` [{"selector": {"sector": "prod", "replicas": 3}, "conditions": {"soak_days": 2}}, {"name": "stage-1", "workloads": null}]`,
+			expected: []PolicyPatch{
+				{
+					Selector: map[string]string{"sector": "prod", "replicas": "3"},
+					Patch:    map[string]interface{}{"conditions": map[string]interface{}{"soak_days": float64(2)}},
+				},
+				{ClusterName: "stage-1", Patch: map[string]interface{}{"workloads": nil}},
+			},
+		},
+		{name: "invalid JSON", input: `{"name": `, expectedErr: true},
+		{name: "name is not a string", input: `{"name": 1}`, expectedErr: true},
+		{name: "selector is not an object", input: `{"selector": "sector=prod"}`, expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches, err := NewPolicyPatchesFromReader(strings.NewReader(tt.input))
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", patches)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(patches, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, patches)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	soakDays := 3
+	current := ClusterUpgradePolicy{
+		ClusterName: "prod-1",
+		Schedule:    "0 10 * * 1-5",
+		Workloads:   []string{"w1"},
+		Conditions: ClusterUpgradePolicyConditions{
+			SoakDays: &soakDays,
+			Sector:   "prod",
+			Mutexes:  []string{"network"},
+		},
+		Owner: "team-a",
+	}
+	tests := []struct {
+		name        string
+		patch       map[string]interface{}
+		expected    func(p *ClusterUpgradePolicy)
+		expectedErr bool
+	}{
+		{
+			name:     "empty patch",
+			patch:    map[string]interface{}{},
+			expected: func(p *ClusterUpgradePolicy) {},
+		},
+		{
+			name:     "replace a value",
+			patch:    map[string]interface{}{"schedule": "0 0 * * *"},
+			expected: func(p *ClusterUpgradePolicy) { p.Schedule = "0 0 * * *" },
+		},
+		{
+			name:  "nested values are merged",
+			patch: map[string]interface{}{"conditions": map[string]interface{}{"soak_days": float64(7)}},
+			expected: func(p *ClusterUpgradePolicy) {
+				soakDays := 7
+				p.Conditions.SoakDays = &soakDays
+			},
+		},
+		{
+			name:     "null removes a value",
+			patch:    map[string]interface{}{"conditions": map[string]interface{}{"mutexes": nil, "sector": nil}, "owner": nil},
+			expected: func(p *ClusterUpgradePolicy) { p.Conditions.Mutexes = nil; p.Conditions.Sector = ""; p.Owner = "" },
+		},
+		{
+			name:     "lists are replaced",
+			patch:    map[string]interface{}{"workloads": []interface{}{"w2", "w3"}},
+			expected: func(p *ClusterUpgradePolicy) { p.Workloads = []string{"w2", "w3"} },
+		},
+		{
+			name:     "the cluster name is kept",
+			patch:    map[string]interface{}{"name": "prod-2"},
+			expected: func(p *ClusterUpgradePolicy) {},
+		},
+		{
+			name:        "wrong type",
+			patch:       map[string]interface{}{"conditions": map[string]interface{}{"soak_days": "many"}},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := current.MergePatch(tt.patch)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", patched)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := current
+			expected.Workloads = append([]string{}, current.Workloads...)
+			expected.Conditions.Mutexes = append([]string{}, current.Conditions.Mutexes...)
+			tt.expected(&expected)
+			if !reflect.DeepEqual(patched, expected) {
+				t.Errorf("expected %+v, got %+v", expected, patched)
+			}
+		})
+	}
+}
@@ -21,39 +21,106 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
+
+	"github.com/app-sre/aus-cli/pkg/versions"
 )
 
 type ClusterUpgradePolicy struct {
-	ClusterName string                         `json:"name"`
-	Schedule    string                         `json:"schedule"`
-	Workloads   []string                       `json:"workloads"`
-	Conditions  ClusterUpgradePolicyConditions `json:"conditions"`
+	ClusterName string `json:"name,omitempty"`
+	// Selector applies the policy to all clusters matching the key=value pairs instead of a
+	// single named cluster.
+	Selector   map[string]string              `json:"selector,omitempty"`
+	Schedule   string                         `json:"schedule,omitempty"`
+	Workloads  []string                       `json:"workloads"`
+	Conditions ClusterUpgradePolicyConditions `json:"conditions"`
+	// Inherited lists the fields whose values come from the organization defaults. These values
+	// are not stored on the cluster when the policy is applied.
+	Inherited []string `json:"inherited,omitempty"`
+	// Owner, Contact and Ticket describe who is responsible for the policy. A policy without
+	// any of them leaves the ownership of the cluster unchanged when it is applied.
+	Owner   string `json:"owner,omitempty"`
+	Contact string `json:"contact,omitempty"`
+	Ticket  string `json:"ticket,omitempty"`
 }
 
 type ClusterUpgradePolicyConditions struct {
-	SoakDays        int      `json:"soak_days"`
+	// SoakDays is nil if the soak days are inherited from the organization defaults.
+	SoakDays        *int     `json:"soak_days,omitempty"`
 	Sector          string   `json:"sector,omitempty"`
 	Mutexes         []string `json:"mutexes,omitempty"`
 	BlockedVersions []string `json:"blocked_versions,omitempty"`
+	// MaxVersion caps upgrades at a minor version stream, e.g. 4.15.
+	MaxVersion string `json:"max_version,omitempty"`
+	// ZStreamOnly restricts the cluster to patch upgrades within its current minor version.
+	ZStreamOnly bool `json:"z_stream_only,omitempty"`
+	// MinUpgradeIntervalDays is the minimum number of days between two upgrades of the cluster.
+	MinUpgradeIntervalDays int `json:"min_upgrade_interval_days,omitempty"`
+	// RequireApproval only allows upgrades to versions approved for the cluster or its sector.
+	RequireApproval bool `json:"require_approval,omitempty"`
+	// Canary marks the cluster as the canary of its workloads. The other clusters of these workloads
+	// are only upgraded to versions the canary runs already.
+	Canary bool `json:"canary,omitempty"`
 }
 
+// Validate checks a complete policy, i.e. a policy merged with the organization defaults.
 func (p ClusterUpgradePolicy) Validate() error {
-	if p.ClusterName == "" {
-		return fmt.Errorf("cluster name is required")
+	if err := p.ValidateExplicit(); err != nil {
+		return err
+	}
+	if p.Schedule == "" {
+		return fmt.Errorf("schedule is required")
+	}
+	return nil
+}
+
+// ValidateExplicit checks the values that can't be inherited from the organization defaults.
+func (p ClusterUpgradePolicy) ValidateExplicit() error {
+	if p.ClusterName == "" && len(p.Selector) == 0 {
+		return fmt.Errorf("cluster name or selector is required")
 	}
-	if p.Conditions.SoakDays < 0 {
+	if p.ClusterName != "" && len(p.Selector) > 0 {
+		return fmt.Errorf("cluster name and selector are mutually exclusive")
+	}
+	if p.Conditions.SoakDays != nil && *p.Conditions.SoakDays < 0 {
 		return fmt.Errorf("soak-days must be >= 0")
 	}
 	if len(p.Workloads) == 0 {
 		return fmt.Errorf("workloads are required")
 	}
-	if p.Schedule == "" {
-		return fmt.Errorf("schedule is required")
+	if p.Conditions.MinUpgradeIntervalDays < 0 {
+		return fmt.Errorf("min-upgrade-interval-days must be >= 0")
+	}
+	if p.Conditions.MaxVersion != "" {
+		if err := versions.ValidateMaxVersion(p.Conditions.MaxVersion); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func NewClusterUpgradePolicy(clusterName string, schedule string, workloads []string, soakDays int, sector string, mutexes []string, blockedVersions []string) ClusterUpgradePolicy {
+func (p ClusterUpgradePolicy) IsInherited(field string) bool {
+	for _, inherited := range p.Inherited {
+		if inherited == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ClusterUpgradePolicy) HasOwnership() bool {
+	return p.Owner != "" || p.Contact != "" || p.Ticket != ""
+}
+
+// SoakDays returns the soak days of the policy, 0 if there are none.
+func (p ClusterUpgradePolicy) SoakDays() int {
+	if p.Conditions.SoakDays == nil {
+		return 0
+	}
+	return *p.Conditions.SoakDays
+}
+
+func NewClusterUpgradePolicy(clusterName string, schedule string, workloads []string, soakDays *int, sector string, mutexes []string, blockedVersions []string, maxVersion string) ClusterUpgradePolicy {
 	return ClusterUpgradePolicy{
 		ClusterName: clusterName,
 		Schedule:    schedule,
@@ -63,6 +130,7 @@ func NewClusterUpgradePolicy(clusterName string, schedule string, workloads []st
 			Sector:          sector,
 			Mutexes:         mutexes,
 			BlockedVersions: blockedVersions,
+			MaxVersion:      maxVersion,
 		},
 	}
 }
@@ -73,6 +141,68 @@ func NewClusterUpgradePolicyFromReader(reader io.Reader) ([]ClusterUpgradePolicy
 	return policies, err
 }
 
+// CanaryConflict is a workload with more than one canary, or a workload that lost its canary.
+type CanaryConflict struct {
+	Workload string
+	Canaries []string
+	// Previous holds the former canaries of a workload that lost its canary.
+	Previous []string
+}
+
+func (c CanaryConflict) Error() string {
+	if len(c.Canaries) == 0 {
+		return fmt.Sprintf("workload %s must keep exactly one canary, but %s is no longer its canary", c.Workload, strings.Join(c.Previous, ", "))
+	}
+	return fmt.Sprintf("workload %s has more than one canary: %s", c.Workload, strings.Join(c.Canaries, ", "))
+}
+
+// CanaryConflicts returns the workloads that don't have exactly one canary, ordered by workload.
+// Only the workloads with a canary in the policies or the previous policies opted into canaries,
+// the other workloads need none.
+func CanaryConflicts(policies []ClusterUpgradePolicy, previous []ClusterUpgradePolicy) []CanaryConflict {
+	canaries := canariesByWorkload(policies)
+	conflicts := []CanaryConflict{}
+	for workload, clusterNames := range canaries {
+		if len(clusterNames) > 1 {
+			conflicts = append(conflicts, CanaryConflict{Workload: workload, Canaries: clusterNames})
+		}
+	}
+	for workload, clusterNames := range canariesByWorkload(previous) {
+		if _, ok := canaries[workload]; !ok {
+			conflicts = append(conflicts, CanaryConflict{Workload: workload, Previous: clusterNames})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Workload < conflicts[j].Workload
+	})
+	return conflicts
+}
+
+func canariesByWorkload(policies []ClusterUpgradePolicy) map[string][]string {
+	canaries := map[string][]string{}
+	for _, p := range policies {
+		if !p.Conditions.Canary {
+			continue
+		}
+		for _, workload := range p.Workloads {
+			canaries[workload] = append(canaries[workload], p.ClusterName)
+		}
+	}
+	for _, clusterNames := range canaries {
+		sort.Strings(clusterNames)
+	}
+	return canaries
+}
+
+// ValidateCanaries checks that every workload that opted into canaries, by having a canary in the
+// policies or the previous policies, has exactly one canary in the policies.
+func ValidateCanaries(policies []ClusterUpgradePolicy, previous []ClusterUpgradePolicy) error {
+	if conflicts := CanaryConflicts(policies, previous); len(conflicts) > 0 {
+		return conflicts[0]
+	}
+	return nil
+}
+
 func SortPolicies(policies []ClusterUpgradePolicy) {
 	sort.Slice(policies, func(i, j int) bool {
 		return policies[i].ClusterName < policies[j].ClusterName
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+)
+
+func TestValidateCanaries(t *testing.T) {
+	policy := func(clusterName string, canary bool, workloads ...string) ClusterUpgradePolicy {
+		return ClusterUpgradePolicy{
+			ClusterName: clusterName,
+			Workloads:   workloads,
+			Conditions:  ClusterUpgradePolicyConditions{Canary: canary},
+		}
+	}
+	tests := []struct {
+		name          string
+		policies      []ClusterUpgradePolicy
+		previous      []ClusterUpgradePolicy
+		expectedError string
+	}{
+		{
+			name:     "no canaries",
+			policies: []ClusterUpgradePolicy{policy("a", false, "w1"), policy("b", false, "w1")},
+		},
+		{
+			name:     "one canary per workload",
+			policies: []ClusterUpgradePolicy{policy("a", true, "w1"), policy("b", true, "w2"), policy("c", false, "w1", "w2")},
+		},
+		{
+			name:     "one canary for several workloads",
+			policies: []ClusterUpgradePolicy{policy("a", true, "w1", "w2"), policy("b", false, "w2")},
+		},
+		{
+			name:          "two canaries",
+			policies:      []ClusterUpgradePolicy{policy("b", true, "w1"), policy("a", true, "w1"), policy("c", false, "w1")},
+			expectedError: "workload w1 has more than one canary: a, b",
+		},
+		{
+			name:          "two canaries sharing one of their workloads",
+			policies:      []ClusterUpgradePolicy{policy("a", true, "w1", "w3"), policy("b", true, "w2", "w3")},
+			expectedError: "workload w3 has more than one canary: a, b",
+		},
+		{
+			name:     "kept canary",
+			policies: []ClusterUpgradePolicy{policy("a", true, "w1"), policy("b", false, "w1")},
+			previous: []ClusterUpgradePolicy{policy("a", true, "w1")},
+		},
+		{
+			name:     "moved canary",
+			policies: []ClusterUpgradePolicy{policy("a", false, "w1"), policy("b", true, "w1")},
+			previous: []ClusterUpgradePolicy{policy("a", true, "w1")},
+		},
+		{
+			name:          "removed canary",
+			policies:      []ClusterUpgradePolicy{policy("a", false, "w1"), policy("b", false, "w1")},
+			previous:      []ClusterUpgradePolicy{policy("a", true, "w1")},
+			expectedError: "workload w1 must keep exactly one canary, but a is no longer its canary",
+		},
+		{
+			name:          "workload removed from its canary",
+			policies:      []ClusterUpgradePolicy{policy("a", true, "w2"), policy("b", false, "w1")},
+			previous:      []ClusterUpgradePolicy{policy("a", true, "w1", "w2")},
+			expectedError: "workload w1 must keep exactly one canary, but a is no longer its canary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCanaries(tt.policies, tt.previous)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() ClusterUpgradePolicy {
+		return NewClusterUpgradePolicy("a", "0 10 * * 1-5", []string{"w1"}, nil, "", nil, nil, "")
+	}
+	tests := []struct {
+		name          string
+		modify        func(p *ClusterUpgradePolicy)
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			modify: func(p *ClusterUpgradePolicy) {},
+		},
+		{
+			name: "z-stream only with a minimum upgrade interval",
+			modify: func(p *ClusterUpgradePolicy) {
+				p.Conditions.ZStreamOnly = true
+				p.Conditions.MinUpgradeIntervalDays = 7
+			},
+		},
+		{
+			name:          "negative minimum upgrade interval",
+			modify:        func(p *ClusterUpgradePolicy) { p.Conditions.MinUpgradeIntervalDays = -1 },
+			expectedError: "min-upgrade-interval-days must be >= 0",
+		},
+		{
+			name:          "missing schedule",
+			modify:        func(p *ClusterUpgradePolicy) { p.Schedule = "" },
+			expectedError: "schedule is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(&p)
+			err := p.Validate()
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
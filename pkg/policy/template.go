@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/template"
+
+	"github.com/app-sre/aus-cli/pkg/schedule"
+)
+
+// PolicyTemplate describes the upgrade policy of all clusters matching the selector. The schedule,
+// workloads, sector, mutexes and blocked versions can contain text/template placeholders that are
+// filled with the attributes of each cluster, see TemplateData.
+type PolicyTemplate struct {
+	Name       string                         `json:"name"`
+	Selector   map[string]string              `json:"selector"`
+	Schedule   string                         `json:"schedule"`
+	Workloads  []string                       `json:"workloads"`
+	Conditions ClusterUpgradePolicyConditions `json:"conditions"`
+}
+
+// TemplateData holds the cluster attributes available to policy templates.
+type TemplateData struct {
+	Name          string
+	ID            string
+	CloudProvider string
+	Region        string
+	Product       string
+	ChannelGroup  string
+	Labels        map[string]string
+}
+
+var templateFuncs = template.FuncMap{
+	"businessHours": schedule.BusinessHours,
+}
+
+func (t PolicyTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if len(t.Selector) == 0 {
+		return fmt.Errorf("selector of template %s is required", t.Name)
+	}
+	// parse all placeholders once to report syntax errors before any cluster is rendered
+	_, err := t.Render(TemplateData{}, true)
+	return err
+}
+
+// Render fills the placeholders of the template with the attributes of a cluster. With parseOnly,
+// the placeholders are only checked for syntax errors.
+func (t PolicyTemplate) Render(data TemplateData, parseOnly bool) (ClusterUpgradePolicy, error) {
+	policy := ClusterUpgradePolicy{
+		ClusterName: data.Name,
+		Conditions: ClusterUpgradePolicyConditions{
+			SoakDays:               t.Conditions.SoakDays,
+			MaxVersion:             t.Conditions.MaxVersion,
+			ZStreamOnly:            t.Conditions.ZStreamOnly,
+			MinUpgradeIntervalDays: t.Conditions.MinUpgradeIntervalDays,
+			RequireApproval:        t.Conditions.RequireApproval,
+			Canary:                 t.Conditions.Canary,
+		},
+	}
+	var err error
+	render := func(field string, value string) string {
+		if err != nil {
+			return ""
+		}
+		var tmpl *template.Template
+		tmpl, err = template.New(field).Funcs(templateFuncs).Option("missingkey=error").Parse(value)
+		if err != nil {
+			err = fmt.Errorf("template %s: %v", t.Name, err)
+			return ""
+		}
+		if parseOnly {
+			return value
+		}
+		var out bytes.Buffer
+		err = tmpl.Execute(&out, data)
+		if err != nil {
+			err = fmt.Errorf("template %s for cluster %s: %v", t.Name, data.Name, err)
+		}
+		return out.String()
+	}
+	renderList := func(field string, values []string) []string {
+		rendered := make([]string, 0, len(values))
+		for _, value := range values {
+			if value := render(field, value); value != "" {
+				rendered = append(rendered, value)
+			}
+		}
+		return rendered
+	}
+
+	policy.Schedule = render("schedule", t.Schedule)
+	policy.Workloads = renderList("workloads", t.Workloads)
+	policy.Conditions.Sector = render("sector", t.Conditions.Sector)
+	policy.Conditions.Mutexes = renderList("mutexes", t.Conditions.Mutexes)
+	policy.Conditions.BlockedVersions = renderList("blocked_versions", t.Conditions.BlockedVersions)
+	if err != nil {
+		return ClusterUpgradePolicy{}, err
+	}
+	if !parseOnly && policy.Schedule != "" {
+		policy.Schedule, err = schedule.TranslateSchedule(policy.Schedule)
+		if err != nil {
+			return ClusterUpgradePolicy{}, fmt.Errorf("template %s for cluster %s: %v", t.Name, data.Name, err)
+		}
+	}
+	return policy, nil
+}
+
+func NewPolicyTemplatesFromReader(reader io.Reader) ([]PolicyTemplate, error) {
+	var templates []PolicyTemplate
+	err := json.NewDecoder(reader).Decode(&templates)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, t := range templates {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate template %s", t.Name)
+		}
+		names[t.Name] = true
+	}
+	return templates, nil
+}
+
+func SortPolicyTemplates(templates []PolicyTemplate) {
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+}
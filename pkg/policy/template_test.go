@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPolicyTemplateRender(t *testing.T) {
+	soakDays := 2
+	data := TemplateData{
+		Name:          "prod-1",
+		ID:            "abc",
+		CloudProvider: "aws",
+		Region:        "eu-west-1",
+		Product:       "rosa",
+		ChannelGroup:  "stable",
+		Labels:        map[string]string{"env": "prod", "team.name": "sre"},
+	}
+	tests := []struct {
+		name          string
+		template      PolicyTemplate
+		expected      ClusterUpgradePolicy
+		expectedError string
+	}{
+		{
+			name: "plain values",
+			template: PolicyTemplate{
+				Name:       "plain",
+				Schedule:   "weekdays",
+				Workloads:  []string{"service"},
+				Conditions: ClusterUpgradePolicyConditions{SoakDays: &soakDays, Canary: true},
+			},
+			expected: ClusterUpgradePolicy{
+				ClusterName: "prod-1",
+				Schedule:    "* * * * 1-4",
+				Workloads:   []string{"service"},
+				Conditions: ClusterUpgradePolicyConditions{
+					SoakDays:        &soakDays,
+					Canary:          true,
+					Mutexes:         []string{},
+					BlockedVersions: []string{},
+				},
+			},
+		},
+		{
+			name: "placeholders",
+			template: PolicyTemplate{
+				Name:      "placeholders",
+				Schedule:  "{{ businessHours .Region }}",
+				Workloads: []string{"{{ .Product }}-{{ .Labels.env }}", `{{ index .Labels "team.name" }}`},
+				Conditions: ClusterUpgradePolicyConditions{
+					Sector:          "{{ .Labels.env }}",
+					Mutexes:         []string{"{{ .CloudProvider }}-{{ .Region }}", "{{ if eq .ChannelGroup \"fast\" }}fast{{ end }}"},
+					BlockedVersions: []string{"^4\\.15\\..*$"},
+				},
+			},
+			expected: ClusterUpgradePolicy{
+				ClusterName: "prod-1",
+				Schedule:    "* 9-16 * * 1-4",
+				Workloads:   []string{"rosa-prod", "sre"},
+				Conditions: ClusterUpgradePolicyConditions{
+					Sector:          "prod",
+					Mutexes:         []string{"aws-eu-west-1"},
+					BlockedVersions: []string{"^4\\.15\\..*$"},
+				},
+			},
+		},
+		{
+			name:          "missing label",
+			template:      PolicyTemplate{Name: "missing", Schedule: "anytime", Workloads: []string{"{{ .Labels.owner }}"}},
+			expectedError: "template missing for cluster prod-1",
+		},
+		{
+			name:          "unknown region",
+			template:      PolicyTemplate{Name: "region", Schedule: "{{ businessHours \"mars-1\" }}", Workloads: []string{"w"}},
+			expectedError: "no business hours known for region mars-1",
+		},
+		{
+			name:          "syntax error",
+			template:      PolicyTemplate{Name: "syntax", Schedule: "anytime", Workloads: []string{"{{ .Name"}},
+			expectedError: "template syntax",
+		},
+		{
+			name:          "invalid rendered schedule",
+			template:      PolicyTemplate{Name: "schedule", Schedule: "{{ .Region }}", Workloads: []string{"w"}},
+			expectedError: "invalid schedule",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := tt.template.Render(data, false)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected an error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(policy, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, policy)
+			}
+		})
+	}
+}
+
+func TestNewPolicyTemplatesFromReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:  "valid",
+			input: `[{"name": "a", "selector": {"product": "rosa"}, "schedule": "{{ businessHours .Region }}", "workloads": ["w"]}]`,
+		},
+		{
+			name:          "missing name",
+			input:         `[{"selector": {"product": "rosa"}}]`,
+			expectedError: "template name is required",
+		},
+		{
+			name:          "missing selector",
+			input:         `[{"name": "a"}]`,
+			expectedError: "selector of template a is required",
+		},
+		{
+			name:          "syntax error",
+			input:         `[{"name": "a", "selector": {"product": "rosa"}, "schedule": "{{ .Region"}]`,
+			expectedError: "template a",
+		},
+		{
+			name:          "duplicate",
+			input:         `[{"name": "a", "selector": {"product": "rosa"}}, {"name": "a", "selector": {"product": "osd"}}]`,
+			expectedError: "duplicate template a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicyTemplatesFromReader(strings.NewReader(tt.input))
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
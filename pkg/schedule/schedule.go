@@ -17,6 +17,10 @@ limitations under the License.
 package schedule
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/pkg/errors"
 	cron "github.com/robfig/cron/v3"
 )
@@ -55,3 +59,97 @@ func TranslateSchedule(schedule string) (string, error) {
 
 	return schedule, nil
 }
+
+// regionUTCOffsets maps cloud region prefixes to the standard UTC offset of the region. The most
+// specific prefix wins.
+var regionUTCOffsets = map[string]int{
+	// AWS
+	"us-east":        -5,
+	"us-west":        -8,
+	"ca-central":     -5,
+	"ca-west":        -7,
+	"sa-east":        -3,
+	"mx-central":     -6,
+	"eu-west":        0,
+	"eu-west-1":      0,
+	"eu-west-2":      0,
+	"eu-west-3":      1,
+	"eu-central":     1,
+	"eu-north":       1,
+	"eu-south":       1,
+	"il-central":     2,
+	"me-central-1":   4,
+	"me-south":       3,
+	"af-south":       2,
+	"ap-south":       5,
+	"ap-east":        8,
+	"ap-southeast":   8,
+	"ap-southeast-1": 8,
+	"ap-southeast-2": 10,
+	"ap-southeast-3": 7,
+	"ap-southeast-4": 10,
+	"ap-northeast-1": 9,
+	"ap-northeast-2": 9,
+	"ap-northeast-3": 9,
+	// GCP, prefixes in namespaces shared with AWS include the region number
+	"us-central1":         -6,
+	"us-east1":            -5,
+	"us-east4":            -5,
+	"us-east5":            -5,
+	"us-south1":           -6,
+	"us-west1":            -8,
+	"us-west2":            -8,
+	"us-west3":            -7,
+	"us-west4":            -8,
+	"northamerica":        -5,
+	"southamerica":        -3,
+	"europe-west1":        1,
+	"europe-west2":        0,
+	"europe-west3":        1,
+	"europe-west4":        1,
+	"europe-west6":        1,
+	"europe-west8":        1,
+	"europe-west9":        1,
+	"europe-north1":       2,
+	"europe-central2":     1,
+	"asia-east":           8,
+	"asia-northeast1":     9,
+	"asia-northeast2":     9,
+	"asia-northeast3":     9,
+	"asia-south":          5,
+	"asia-southeast1":     8,
+	"asia-southeast2":     7,
+	"australia-southeast": 10,
+	"me-west1":            3,
+	"me-central1":         3,
+	"me-central2":         3,
+}
+
+const (
+	businessHoursStart = 9
+	businessHoursEnd   = 16
+)
+
+// BusinessHours returns a schedule that allows upgrades to start between 9:00 and 16:59 local time
+// of the given cloud region, Monday to Thursday. The schedule is an approximation: schedules are
+// evaluated in UTC and a cron expression can't shift the weekdays for a part of the hours, so the
+// window is cut where it crosses UTC midnight. Daylight saving time is not considered.
+func BusinessHours(region string) (string, error) {
+	prefixes := make([]string, 0, len(regionUTCOffsets))
+	for prefix := range regionUTCOffsets {
+		prefixes = append(prefixes, prefix)
+	}
+	// longest prefix first
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(region, prefix) {
+			offset := regionUTCOffsets[prefix]
+			start := max(businessHoursStart-offset, 0)
+			end := min(businessHoursEnd-offset, 23)
+			return fmt.Sprintf("* %d-%d * * 1-4", start, end), nil
+		}
+	}
+	return "", fmt.Errorf("no business hours known for region %s", region)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+)
+
+func TestBusinessHours(t *testing.T) {
+	tests := []struct {
+		region      string
+		expected    string
+		expectedErr bool
+	}{
+		// AWS
+		{region: "us-east-1", expected: "* 14-21 * * 1-4"},
+		{region: "us-west-2", expected: "* 17-23 * * 1-4"},
+		{region: "eu-west-1", expected: "* 9-16 * * 1-4"},
+		{region: "eu-west-3", expected: "* 8-15 * * 1-4"},
+		{region: "eu-west-4", expected: "* 9-16 * * 1-4"},
+		{region: "eu-central-1", expected: "* 8-15 * * 1-4"},
+		{region: "me-central-1", expected: "* 5-12 * * 1-4"},
+		{region: "ap-southeast-2", expected: "* 0-6 * * 1-4"},
+		{region: "ap-southeast-5", expected: "* 1-8 * * 1-4"},
+		{region: "ap-northeast-1", expected: "* 0-7 * * 1-4"},
+		// GCP
+		{region: "us-central1", expected: "* 15-22 * * 1-4"},
+		{region: "us-east1", expected: "* 14-21 * * 1-4"},
+		{region: "us-west1", expected: "* 17-23 * * 1-4"},
+		{region: "us-west3", expected: "* 16-23 * * 1-4"},
+		{region: "europe-west2", expected: "* 9-16 * * 1-4"},
+		{region: "europe-west3", expected: "* 8-15 * * 1-4"},
+		{region: "me-central1", expected: "* 6-13 * * 1-4"},
+		{region: "australia-southeast1", expected: "* 0-6 * * 1-4"},
+		// unknown regions
+		{region: "us-central-1", expectedErr: true},
+		{region: "us-gov-west-1", expectedErr: true},
+		{region: "", expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			schedule, err := BusinessHours(tt.region)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", schedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if schedule != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, schedule)
+			}
+			if _, err := TranslateSchedule(schedule); err != nil {
+				t.Errorf("expected a valid schedule: %v", err)
+			}
+		})
+	}
+}
+
+func TestTranslateSchedule(t *testing.T) {
+	tests := []struct {
+		schedule    string
+		expected    string
+		expectedErr bool
+	}{
+		{schedule: "weekdays", expected: "* * * * 1-4"},
+		{schedule: "anytime", expected: "* * * * *"},
+		{schedule: "0 10 * * 1-5", expected: "0 10 * * 1-5"},
+		{schedule: "", expectedErr: true},
+		{schedule: "sometimes", expectedErr: true},
+		{schedule: "0 25 * * *", expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			schedule, err := TranslateSchedule(tt.schedule)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", schedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if schedule != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, schedule)
+			}
+		})
+	}
+}
@@ -18,7 +18,9 @@ package sectors
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -202,3 +204,26 @@ func ReadSectorsFromReader(reader io.Reader) ([]Sector, error) {
 	err := json.NewDecoder(reader).Decode(&sectorList)
 	return sectorList, err
 }
+
+var maxParallelUpgradesPattern = regexp.MustCompile(`^[0-9]+%?$`)
+
+// Validate checks a complete sector configuration.
+func Validate(sectors []Sector) error {
+	names := make(map[string]bool)
+	for _, sector := range sectors {
+		if sector.Name == "" {
+			return fmt.Errorf("sector name is required")
+		}
+		if names[sector.Name] {
+			return fmt.Errorf("sector %s is defined more than once", sector.Name)
+		}
+		names[sector.Name] = true
+		if sector.DependsOn(sector.Name) {
+			return fmt.Errorf("sector %s can't depend on itself", sector.Name)
+		}
+		if sector.MaxParallelUpgrades != "" && !maxParallelUpgradesPattern.MatchString(sector.MaxParallelUpgrades) {
+			return fmt.Errorf("max parallel upgrades of sector %s must be a number or a percentage, got %s", sector.Name, sector.MaxParallelUpgrades)
+		}
+	}
+	return nil
+}
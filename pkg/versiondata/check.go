@@ -0,0 +1,217 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versiondata
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// OrganizationInheritance is the inheritance configuration of an organization in an environment.
+type OrganizationInheritance struct {
+	OrganizationId string
+	Name           string
+	Environment    string
+	Config         VersionDataInheritanceConfig
+}
+
+// InheritanceLookup returns the inheritance configuration of an organization.
+type InheritanceLookup func(organizationId string) (*OrganizationInheritance, error)
+
+// InheritanceGraph holds the organizations reachable from a start organization by following
+// inherit and publish references.
+type InheritanceGraph struct {
+	Organizations map[string]*OrganizationInheritance
+	// Unreachable holds the referenced organizations that could not be looked up.
+	Unreachable map[string]error
+}
+
+// WalkInheritance follows inherit and publish references transitively, starting with the given
+// organization.
+func WalkInheritance(organizationId string, lookup InheritanceLookup) *InheritanceGraph {
+	graph := &InheritanceGraph{
+		Organizations: make(map[string]*OrganizationInheritance),
+		Unreachable:   make(map[string]error),
+	}
+	queue := []string{organizationId}
+	for len(queue) > 0 {
+		orgId := queue[0]
+		queue = queue[1:]
+		if _, ok := graph.Organizations[orgId]; ok {
+			continue
+		}
+		if _, ok := graph.Unreachable[orgId]; ok {
+			continue
+		}
+		org, err := lookup(orgId)
+		if err != nil {
+			graph.Unreachable[orgId] = err
+			continue
+		}
+		graph.Organizations[orgId] = org
+		queue = append(queue, splitList(org.Config.InheritingFromOrgs)...)
+		queue = append(queue, splitList(org.Config.PublishingToOrgs)...)
+	}
+	return graph
+}
+
+// SortedOrganizations returns the reachable organizations ordered by ID.
+func (g *InheritanceGraph) SortedOrganizations() []*OrganizationInheritance {
+	orgs := make([]*OrganizationInheritance, 0, len(g.Organizations))
+	for _, org := range g.Organizations {
+		orgs = append(orgs, org)
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		return orgs[i].OrganizationId < orgs[j].OrganizationId
+	})
+	return orgs
+}
+
+type InheritanceProblemKind string
+
+const (
+	OneSidedInheritance InheritanceProblemKind = "one-sided inheritance"
+	OneSidedPublishing  InheritanceProblemKind = "one-sided publishing"
+	WorkloadMismatch    InheritanceProblemKind = "workload mismatch"
+	UnreachableOrg      InheritanceProblemKind = "unreachable organization"
+	InheritanceCycle    InheritanceProblemKind = "inheritance cycle"
+)
+
+type InheritanceProblem struct {
+	Kind    InheritanceProblemKind
+	Message string
+}
+
+// Check reports inherit references without a matching publish reference and vice versa,
+// organizations that could not be looked up and cycles of inherit references.
+func (g *InheritanceGraph) Check() []InheritanceProblem {
+	problems := []InheritanceProblem{}
+	for _, org := range g.SortedOrganizations() {
+		for _, from := range splitList(org.Config.InheritingFromOrgs) {
+			publisher, ok := g.Organizations[from]
+			if !ok {
+				continue
+			}
+			if !contains(publisher.Config.PublishingToOrgs, org.OrganizationId) {
+				problems = append(problems, InheritanceProblem{
+					Kind: OneSidedInheritance,
+					Message: fmt.Sprintf(
+						"%s inherits from %s, but %s does not publish to %s",
+						org.OrganizationId, from, from, org.OrganizationId,
+					),
+				})
+				continue
+			}
+			published, filtered := publisher.Config.PublishWorkloads[org.OrganizationId]
+			if !filtered {
+				continue
+			}
+			missing := []string{}
+			for _, workload := range org.Config.InheritWorkloads[from] {
+				if !slices.Contains(published, workload) {
+					missing = append(missing, workload)
+				}
+			}
+			if len(missing) > 0 {
+				problems = append(problems, InheritanceProblem{
+					Kind: WorkloadMismatch,
+					Message: fmt.Sprintf(
+						"%s inherits workloads %s from %s, but %s publishes only %s to %s",
+						org.OrganizationId, strings.Join(missing, ", "), from,
+						from, strings.Join(published, ", "), org.OrganizationId,
+					),
+				})
+			}
+		}
+		for _, to := range splitList(org.Config.PublishingToOrgs) {
+			inheritor, ok := g.Organizations[to]
+			if ok && !contains(inheritor.Config.InheritingFromOrgs, org.OrganizationId) {
+				problems = append(problems, InheritanceProblem{
+					Kind: OneSidedPublishing,
+					Message: fmt.Sprintf(
+						"%s publishes to %s, but %s does not inherit from %s",
+						org.OrganizationId, to, to, org.OrganizationId,
+					),
+				})
+			}
+		}
+	}
+
+	unreachable := make([]string, 0, len(g.Unreachable))
+	for orgId := range g.Unreachable {
+		unreachable = append(unreachable, orgId)
+	}
+	sort.Strings(unreachable)
+	for _, orgId := range unreachable {
+		problems = append(problems, InheritanceProblem{
+			Kind:    UnreachableOrg,
+			Message: fmt.Sprintf("%s: %v", orgId, g.Unreachable[orgId]),
+		})
+	}
+
+	for _, cycle := range g.inheritanceCycles() {
+		problems = append(problems, InheritanceProblem{
+			Kind:    InheritanceCycle,
+			Message: strings.Join(cycle, " inherits from "),
+		})
+	}
+	return problems
+}
+
+// inheritanceCycles returns every cycle of inherit references once, starting with its smallest
+// organization ID.
+func (g *InheritanceGraph) inheritanceCycles() [][]string {
+	cycles := [][]string{}
+	seen := map[string]bool{}
+	var visit func(path []string)
+	visit = func(path []string) {
+		current := path[len(path)-1]
+		org, ok := g.Organizations[current]
+		if !ok {
+			return
+		}
+		for _, next := range splitList(org.Config.InheritingFromOrgs) {
+			// only report cycles from their smallest member to avoid duplicates
+			if next < path[0] {
+				continue
+			}
+			if next == path[0] {
+				cycle := append(append([]string{}, path...), next)
+				key := strings.Join(cycle, ",")
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+				continue
+			}
+			if contains(path, next) {
+				continue
+			}
+			visit(append(append([]string{}, path...), next))
+		}
+	}
+	for _, org := range g.SortedOrganizations() {
+		visit([]string{org.OrganizationId})
+	}
+	return cycles
+}
+
+func contains(values []string, value string) bool {
+	return slices.Contains(splitList(values), value)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versiondata
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// testLookup looks up organizations in a fixed set, other organizations are unreachable.
+func testLookup(configs map[string]VersionDataInheritanceConfig) InheritanceLookup {
+	return func(organizationId string) (*OrganizationInheritance, error) {
+		config, ok := configs[organizationId]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return &OrganizationInheritance{OrganizationId: organizationId, Environment: "production", Config: config}, nil
+	}
+}
+
+func TestWalkInheritance(t *testing.T) {
+	graph := WalkInheritance("a", testLookup(map[string]VersionDataInheritanceConfig{
+		"a": {InheritingFromOrgs: []string{"b"}},
+		"b": {PublishingToOrgs: []string{"a,c"}},
+		"c": {InheritingFromOrgs: []string{"b"}, PublishingToOrgs: []string{"gone"}},
+		"d": {PublishingToOrgs: []string{"a"}},
+	}))
+	orgIds := []string{}
+	for _, org := range graph.SortedOrganizations() {
+		orgIds = append(orgIds, org.OrganizationId)
+	}
+	// d publishes to a, but isn't referenced by any reachable organization
+	if !reflect.DeepEqual(orgIds, []string{"a", "b", "c"}) {
+		t.Errorf("expected organizations a, b and c, got %v", orgIds)
+	}
+	if _, ok := graph.Unreachable["gone"]; !ok || len(graph.Unreachable) != 1 {
+		t.Errorf("expected only gone to be unreachable, got %v", graph.Unreachable)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		configs  map[string]VersionDataInheritanceConfig
+		expected []InheritanceProblem
+	}{
+		{
+			name:  "reciprocal",
+			start: "a",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"b"}},
+				"b": {PublishingToOrgs: []string{"a", "c"}, PublishWorkloads: map[string][]string{"c": {"w1", "w2"}}},
+				"c": {InheritingFromOrgs: []string{"b"}, InheritWorkloads: map[string][]string{"b": {"w1"}}},
+			},
+			expected: []InheritanceProblem{},
+		},
+		{
+			name:  "one-sided inherit",
+			start: "a",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"b"}},
+				"b": {},
+			},
+			expected: []InheritanceProblem{
+				{Kind: OneSidedInheritance, Message: "a inherits from b, but b does not publish to a"},
+			},
+		},
+		{
+			name:  "one-sided publish",
+			start: "b",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {},
+				"b": {PublishingToOrgs: []string{"a"}},
+			},
+			expected: []InheritanceProblem{
+				{Kind: OneSidedPublishing, Message: "b publishes to a, but a does not inherit from b"},
+			},
+		},
+		{
+			name:  "workload mismatch",
+			start: "a",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"b"}, InheritWorkloads: map[string][]string{"b": {"w1", "w2"}}},
+				"b": {PublishingToOrgs: []string{"a"}, PublishWorkloads: map[string][]string{"a": {"w1"}}},
+			},
+			expected: []InheritanceProblem{
+				{Kind: WorkloadMismatch, Message: "a inherits workloads w2 from b, but b publishes only w1 to a"},
+			},
+		},
+		{
+			name:  "unreachable",
+			start: "a",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"gone"}},
+			},
+			expected: []InheritanceProblem{
+				{Kind: UnreachableOrg, Message: "gone: not found"},
+			},
+		},
+		{
+			name:  "2-cycle",
+			start: "b",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"b"}, PublishingToOrgs: []string{"b"}},
+				"b": {InheritingFromOrgs: []string{"a"}, PublishingToOrgs: []string{"a"}},
+			},
+			expected: []InheritanceProblem{
+				{Kind: InheritanceCycle, Message: "a inherits from b inherits from a"},
+			},
+		},
+		{
+			name:  "3-cycle",
+			start: "c",
+			configs: map[string]VersionDataInheritanceConfig{
+				"a": {InheritingFromOrgs: []string{"c"}, PublishingToOrgs: []string{"b"}},
+				"b": {InheritingFromOrgs: []string{"a"}, PublishingToOrgs: []string{"c"}},
+				"c": {InheritingFromOrgs: []string{"b"}, PublishingToOrgs: []string{"a"}},
+			},
+			expected: []InheritanceProblem{
+				{Kind: InheritanceCycle, Message: "a inherits from c inherits from b inherits from a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := WalkInheritance(tt.start, testLookup(tt.configs)).Check()
+			if !reflect.DeepEqual(problems, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, problems)
+			}
+		})
+	}
+}
+
+func TestInheritanceCyclesReportedOnce(t *testing.T) {
+	// two cycles through a, each reported once from its smallest member
+	graph := WalkInheritance("a", testLookup(map[string]VersionDataInheritanceConfig{
+		"a": {InheritingFromOrgs: []string{"b", "c"}},
+		"b": {InheritingFromOrgs: []string{"a"}},
+		"c": {InheritingFromOrgs: []string{"b"}},
+	}))
+	cycles := []string{}
+	for _, problem := range graph.Check() {
+		if problem.Kind == InheritanceCycle {
+			cycles = append(cycles, problem.Message)
+		}
+	}
+	sort.Strings(cycles)
+	expected := []string{"a inherits from b inherits from a", "a inherits from c inherits from b inherits from a"}
+	if !reflect.DeepEqual(cycles, expected) {
+		t.Errorf("expected cycles %v, got %v", expected, cycles)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versiondata
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// InheritanceEdge is a version data flow from a publishing to an inheriting organization. An edge
+// is only effective if both sides declare it.
+type InheritanceEdge struct {
+	From      string
+	To        string
+	Published bool
+	Inherited bool
+	// Workloads restricts the version data to some workloads, empty means all workloads.
+	Workloads []string
+}
+
+func (e InheritanceEdge) label() string {
+	label := strings.Join(e.Workloads, ", ")
+	switch {
+	case e.Published && e.Inherited:
+		return label
+	case e.Published:
+		return strings.TrimSpace("publish only " + label)
+	default:
+		return strings.TrimSpace("inherit only " + label)
+	}
+}
+
+func (e InheritanceEdge) confirmed() bool {
+	return e.Published && e.Inherited
+}
+
+// restrict limits the workloads of the edge to the given filter.
+func (e *InheritanceEdge) restrict(workloads []string, filtered bool) {
+	if !filtered {
+		return
+	}
+	if e.Workloads == nil {
+		e.Workloads = append([]string{}, workloads...)
+		return
+	}
+	restricted := []string{}
+	for _, workload := range e.Workloads {
+		if slices.Contains(workloads, workload) {
+			restricted = append(restricted, workload)
+		}
+	}
+	e.Workloads = restricted
+}
+
+// Edges returns all version data flows declared by any organization of the graph.
+func (g *InheritanceGraph) Edges() []InheritanceEdge {
+	edges := map[[2]string]*InheritanceEdge{}
+	edge := func(from string, to string) *InheritanceEdge {
+		key := [2]string{from, to}
+		if _, ok := edges[key]; !ok {
+			edges[key] = &InheritanceEdge{From: from, To: to}
+		}
+		return edges[key]
+	}
+	for _, org := range g.Organizations {
+		for _, to := range splitList(org.Config.PublishingToOrgs) {
+			e := edge(org.OrganizationId, to)
+			e.Published = true
+			workloads, filtered := org.Config.PublishWorkloads[to]
+			e.restrict(workloads, filtered)
+		}
+		for _, from := range splitList(org.Config.InheritingFromOrgs) {
+			e := edge(from, org.OrganizationId)
+			e.Inherited = true
+			workloads, filtered := org.Config.InheritWorkloads[from]
+			e.restrict(workloads, filtered)
+		}
+	}
+	result := make([]InheritanceEdge, 0, len(edges))
+	for _, e := range edges {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// graphNode is an organization of the graph as it is rendered.
+type graphNode struct {
+	id          string
+	label       string
+	unreachable bool
+}
+
+// nodesByEnvironment returns the nodes of the graph grouped by OCM environment. Unreachable
+// organizations are grouped under an empty environment.
+func (g *InheritanceGraph) nodesByEnvironment() (map[string]string, []string, map[string][]graphNode) {
+	orgIds := []string{}
+	for orgId := range g.Organizations {
+		orgIds = append(orgIds, orgId)
+	}
+	for orgId := range g.Unreachable {
+		orgIds = append(orgIds, orgId)
+	}
+	for _, e := range g.Edges() {
+		orgIds = append(orgIds, e.From, e.To)
+	}
+	sort.Strings(orgIds)
+
+	nodeIds := map[string]string{}
+	groups := map[string][]graphNode{}
+	for _, orgId := range orgIds {
+		if _, ok := nodeIds[orgId]; ok {
+			continue
+		}
+		nodeId := fmt.Sprintf("n%d", len(nodeIds))
+		nodeIds[orgId] = nodeId
+		environment := ""
+		label := fmt.Sprintf("%s\\n(unreachable)", orgId)
+		org, ok := g.Organizations[orgId]
+		if ok {
+			environment = org.Environment
+			label = orgId
+			if org.Name != "" {
+				label = fmt.Sprintf("%s\\n%s", org.Name, orgId)
+			}
+		}
+		groups[environment] = append(groups[environment], graphNode{id: nodeId, label: label, unreachable: !ok})
+	}
+	environments := []string{}
+	for environment := range groups {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+	return nodeIds, environments, groups
+}
+
+// Dot renders the graph in the Graphviz DOT language. Organizations are grouped by OCM environment
+// and edges point in the direction version data flows.
+func (g *InheritanceGraph) Dot() string {
+	nodeIds, environments, groups := g.nodesByEnvironment()
+	var b strings.Builder
+	b.WriteString("digraph inheritance {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for i, environment := range environments {
+		indent := "  "
+		if environment != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "    label=%q;\n", environment)
+			indent = "    "
+		}
+		for _, node := range groups[environment] {
+			style := ""
+			if node.unreachable {
+				style = ", style=dashed, color=red"
+			}
+			fmt.Fprintf(&b, "%s%s [label=\"%s\"%s];\n", indent, node.id, strings.ReplaceAll(node.label, `"`, `\"`), style)
+		}
+		if environment != "" {
+			b.WriteString("  }\n")
+		}
+	}
+	for _, e := range g.Edges() {
+		attributes := ""
+		if label := e.label(); !e.confirmed() {
+			attributes = fmt.Sprintf(" [label=%q, style=dashed, color=red]", label)
+		} else if label != "" {
+			attributes = fmt.Sprintf(" [label=%q]", label)
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", nodeIds[e.From], nodeIds[e.To], attributes)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Organizations are grouped by OCM environment
+// and edges point in the direction version data flows.
+func (g *InheritanceGraph) Mermaid() string {
+	nodeIds, environments, groups := g.nodesByEnvironment()
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, environment := range environments {
+		indent := "  "
+		if environment != "" {
+			fmt.Fprintf(&b, "  subgraph env%d [\"%s\"]\n", i, mermaidEscape(environment))
+			indent = "    "
+		}
+		for _, node := range groups[environment] {
+			label := strings.ReplaceAll(mermaidEscape(node.label), `\n`, "<br>")
+			fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, node.id, label)
+		}
+		if environment != "" {
+			b.WriteString("  end\n")
+		}
+	}
+	for _, e := range g.Edges() {
+		label := e.label()
+		switch {
+		case !e.confirmed():
+			fmt.Fprintf(&b, "  %s -. %s .-> %s\n", nodeIds[e.From], label, nodeIds[e.To])
+		case label != "":
+			fmt.Fprintf(&b, "  %s -- %s --> %s\n", nodeIds[e.From], label, nodeIds[e.To])
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", nodeIds[e.From], nodeIds[e.To])
+		}
+	}
+	for _, environment := range environments {
+		for _, node := range groups[environment] {
+			if node.unreachable {
+				fmt.Fprintf(&b, "  style %s stroke:#f00,stroke-dasharray: 5 5\n", node.id)
+			}
+		}
+	}
+	return b.String()
+}
+
+// mermaidEscape escapes the quotes of a quoted Mermaid label.
+func mermaidEscape(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versiondata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testGraph() *InheritanceGraph {
+	return &InheritanceGraph{
+		Organizations: map[string]*OrganizationInheritance{
+			"org-a": {
+				OrganizationId: "org-a",
+				Name:           `Team "A"`,
+				Environment:    "production",
+				Config: VersionDataInheritanceConfig{
+					PublishingToOrgs: []string{"org-b", "org-c"},
+					PublishWorkloads: map[string][]string{"org-b": {"w1", "w2"}},
+				},
+			},
+			"org-b": {
+				OrganizationId: "org-b",
+				Environment:    "production",
+				Config: VersionDataInheritanceConfig{
+					InheritingFromOrgs: []string{"org-a"},
+					InheritWorkloads:   map[string][]string{"org-a": {"w1"}},
+				},
+			},
+			"org-c": {
+				OrganizationId: "org-c",
+				Environment:    `stage "eu"`,
+			},
+		},
+		Unreachable: map[string]error{`org "gone"`: errors.New("not found")},
+	}
+}
+
+func TestEdges(t *testing.T) {
+	expected := []InheritanceEdge{
+		// the inherited workloads narrow down the published ones
+		{From: "org-a", To: "org-b", Published: true, Inherited: true, Workloads: []string{"w1"}},
+		{From: "org-a", To: "org-c", Published: true},
+	}
+	if edges := testGraph().Edges(); !reflect.DeepEqual(edges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, edges)
+	}
+}
+
+func TestDot(t *testing.T) {
+	expected := `digraph inheritance {
+  rankdir=LR;
+  node [shape=box];
+  n0 [label="org \"gone\"\n(unreachable)", style=dashed, color=red];
+  subgraph cluster_1 {
+    label="production";
+    n1 [label="Team \"A\"\norg-a"];
+    n2 [label="org-b"];
+  }
+  subgraph cluster_2 {
+    label="stage \"eu\"";
+    n3 [label="org-c"];
+  }
+  n1 -> n2 [label="w1"];
+  n1 -> n3 [label="publish only", style=dashed, color=red];
+}
+`
+	if dot := testGraph().Dot(); dot != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, dot)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	expected := `flowchart LR
+  n0["org #quot;gone#quot;<br>(unreachable)"]
+  subgraph env1 ["production"]
+    n1["Team #quot;A#quot;<br>org-a"]
+    n2["org-b"]
+  end
+  subgraph env2 ["stage #quot;eu#quot;"]
+    n3["org-c"]
+  end
+  n1 -- w1 --> n2
+  n1 -. publish only .-> n3
+  style n0 stroke:#f00,stroke-dasharray: 5 5
+`
+	if mermaid := testGraph().Mermaid(); mermaid != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, mermaid)
+	}
+}
@@ -18,7 +18,11 @@ package versiondata
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type VersionDataInheritanceUpdateMode int64
@@ -31,6 +35,10 @@ const (
 type VersionDataInheritanceConfig struct {
 	InheritingFromOrgs []string `json:"inherit,omitempty"`
 	PublishingToOrgs   []string `json:"publish,omitempty"`
+	// InheritWorkloads and PublishWorkloads restrict the version data inherited from or published to
+	// an organization to some workloads. Organizations without an entry share all workloads.
+	InheritWorkloads map[string][]string `json:"inherit_workloads,omitempty"`
+	PublishWorkloads map[string][]string `json:"publish_workloads,omitempty"`
 }
 
 func NewVersionDataInheritanceConfigFromReader(reader io.Reader) (VersionDataInheritanceConfig, error) {
@@ -39,35 +47,207 @@ func NewVersionDataInheritanceConfigFromReader(reader io.Reader) (VersionDataInh
 	return config, err
 }
 
-func ConsolidateVersionDataInheritanceConfig(current VersionDataInheritanceConfig, desired VersionDataInheritanceConfig) VersionDataInheritanceConfig {
-	// merge the inheriting orgs lists without duplicates
-	inheritingFromOrgsMap := make(map[string]bool)
-	for _, s := range current.InheritingFromOrgs {
-		inheritingFromOrgsMap[s] = true
+// NewVersionDataInheritanceConfigFromEntries builds a configuration from inherit-from and
+// publish-to entries. An entry is either a comma-separated list of organization IDs or a single
+// organization ID with a workload filter, e.g. `org:workload1,workload2`.
+func NewVersionDataInheritanceConfigFromEntries(inherit []string, publish []string) (VersionDataInheritanceConfig, error) {
+	inheritOrgIds, inheritWorkloads, err := ParseOrgEntries(inherit)
+	if err != nil {
+		return VersionDataInheritanceConfig{}, err
 	}
-	for _, s := range desired.InheritingFromOrgs {
-		inheritingFromOrgsMap[s] = true
+	publishOrgIds, publishWorkloads, err := ParseOrgEntries(publish)
+	if err != nil {
+		return VersionDataInheritanceConfig{}, err
 	}
-	inheritingFromOrgs := []string{}
-	for s := range inheritingFromOrgsMap {
-		inheritingFromOrgs = append(inheritingFromOrgs, s)
+	return VersionDataInheritanceConfig{
+		InheritingFromOrgs: inheritOrgIds,
+		PublishingToOrgs:   publishOrgIds,
+		InheritWorkloads:   inheritWorkloads,
+		PublishWorkloads:   publishWorkloads,
+	}, nil
+}
+
+// NewRemovedVersionDataInheritanceConfigFromEntries builds the configuration of the organizations
+// to no longer inherit from or publish to. Removal is by organization ID, so entries with workload
+// filters are rejected instead of silently matching nothing.
+func NewRemovedVersionDataInheritanceConfigFromEntries(inherit []string, publish []string) (VersionDataInheritanceConfig, error) {
+	for _, entry := range append(append([]string{}, inherit...), publish...) {
+		if strings.Contains(entry, ":") {
+			return VersionDataInheritanceConfig{}, fmt.Errorf("invalid entry '%s', organizations are removed with all their workloads, to change a workload filter add the organization again with the new filter", entry)
+		}
+	}
+	return VersionDataInheritanceConfig{
+		InheritingFromOrgs: splitList(inherit),
+		PublishingToOrgs:   splitList(publish),
+	}, nil
+}
+
+// ParseOrgEntries parses organization entries with optional workload filters.
+func ParseOrgEntries(entries []string) ([]string, map[string][]string, error) {
+	orgIds := []string{}
+	workloads := map[string][]string{}
+	for _, entry := range entries {
+		orgId, filter, found := strings.Cut(entry, ":")
+		if !found {
+			orgIds = append(orgIds, splitList([]string{entry})...)
+			continue
+		}
+		orgId = strings.TrimSpace(orgId)
+		if orgId == "" || strings.Contains(orgId, ",") {
+			return nil, nil, fmt.Errorf("invalid entry '%s', a workload filter applies to a single organization, e.g. org:workload1,workload2", entry)
+		}
+		filterWorkloads := splitList([]string{filter})
+		if len(filterWorkloads) == 0 {
+			return nil, nil, fmt.Errorf("invalid entry '%s', the workload filter is empty", entry)
+		}
+		orgIds = append(orgIds, orgId)
+		workloads[orgId] = append(workloads[orgId], filterWorkloads...)
+	}
+	if len(workloads) == 0 {
+		workloads = nil
+	}
+	return orgIds, workloads, nil
+}
+
+// Validate checks that workload filters refer to listed organizations.
+func (c VersionDataInheritanceConfig) Validate() error {
+	for orgId := range c.InheritWorkloads {
+		if !contains(c.InheritingFromOrgs, orgId) {
+			return fmt.Errorf("workload filter for organization %s, which is not inherited from", orgId)
+		}
+	}
+	for orgId := range c.PublishWorkloads {
+		if !contains(c.PublishingToOrgs, orgId) {
+			return fmt.Errorf("workload filter for organization %s, which is not published to", orgId)
+		}
 	}
+	return nil
+}
 
-	// merge the publishing orgs lists without duplicates
-	publishingToOrgsMap := make(map[string]bool)
-	for _, s := range current.PublishingToOrgs {
-		publishingToOrgsMap[s] = true
+// ValidateWorkloads checks that the workload filters only refer to the given workloads, which are
+// usually the ones used by the policies of the organization.
+func (c VersionDataInheritanceConfig) ValidateWorkloads(workloads []string) error {
+	problems := []string{}
+	check := func(direction string, filters map[string][]string) {
+		for _, orgId := range sortedKeys(filters) {
+			for _, workload := range filters[orgId] {
+				if !slices.Contains(workloads, workload) {
+					problems = append(problems, fmt.Sprintf("workload %s %s organization %s", workload, direction, orgId))
+				}
+			}
+		}
 	}
-	for _, s := range desired.PublishingToOrgs {
-		publishingToOrgsMap[s] = true
+	check("inherited from", c.InheritWorkloads)
+	check("published to", c.PublishWorkloads)
+	if len(problems) > 0 {
+		return fmt.Errorf("workload filters refer to workloads that no policy uses: %s", strings.Join(problems, ", "))
 	}
-	publishingToOrgs := []string{}
-	for s := range publishingToOrgsMap {
-		publishingToOrgs = append(publishingToOrgs, s)
+	return nil
+}
+
+// InheritEntries returns the inherited organizations with their workload filters for display.
+func (c VersionDataInheritanceConfig) InheritEntries() []string {
+	return formatOrgEntries(c.InheritingFromOrgs, c.InheritWorkloads)
+}
+
+// PublishEntries returns the organizations published to with their workload filters for display.
+func (c VersionDataInheritanceConfig) PublishEntries() []string {
+	return formatOrgEntries(c.PublishingToOrgs, c.PublishWorkloads)
+}
+
+func formatOrgEntries(orgIds []string, workloads map[string][]string) []string {
+	entries := []string{}
+	for _, orgId := range splitList(orgIds) {
+		if filter, ok := workloads[orgId]; ok {
+			entries = append(entries, fmt.Sprintf("%s (%s)", orgId, strings.Join(filter, ", ")))
+		} else {
+			entries = append(entries, orgId)
+		}
 	}
+	return entries
+}
 
+// ConsolidateVersionDataInheritanceConfig merges the desired organization IDs into the current
+// configuration and drops the removed ones. Workload filters of desired organizations replace the
+// current ones, a desired organization without a filter shares all workloads again. Removed
+// organizations are dropped with their workload filters, removing an unlisted one has no effect.
+func ConsolidateVersionDataInheritanceConfig(current VersionDataInheritanceConfig, desired VersionDataInheritanceConfig, removed VersionDataInheritanceConfig) VersionDataInheritanceConfig {
+	inheritingFromOrgs := consolidateOrgIds(current.InheritingFromOrgs, desired.InheritingFromOrgs, removed.InheritingFromOrgs)
+	publishingToOrgs := consolidateOrgIds(current.PublishingToOrgs, desired.PublishingToOrgs, removed.PublishingToOrgs)
 	return VersionDataInheritanceConfig{
 		InheritingFromOrgs: inheritingFromOrgs,
 		PublishingToOrgs:   publishingToOrgs,
+		InheritWorkloads:   consolidateWorkloads(inheritingFromOrgs, current.InheritWorkloads, desired.InheritingFromOrgs, desired.InheritWorkloads),
+		PublishWorkloads:   consolidateWorkloads(publishingToOrgs, current.PublishWorkloads, desired.PublishingToOrgs, desired.PublishWorkloads),
+	}
+}
+
+func consolidateWorkloads(orgIds []string, current map[string][]string, desiredOrgIds []string, desired map[string][]string) map[string][]string {
+	workloads := map[string][]string{}
+	for orgId, filter := range current {
+		workloads[orgId] = filter
+	}
+	for _, orgId := range splitList(desiredOrgIds) {
+		delete(workloads, orgId)
+	}
+	for orgId, filter := range desired {
+		workloads[orgId] = filter
+	}
+
+	result := map[string][]string{}
+	for orgId, filter := range workloads {
+		if !slices.Contains(orgIds, orgId) {
+			continue
+		}
+		filter = splitList(filter)
+		sort.Strings(filter)
+		result[orgId] = slices.Compact(filter)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// consolidateOrgIds merges lists of organization IDs without duplicates. Entries can be
+// comma-separated lists themselves.
+func consolidateOrgIds(current []string, adding []string, removing []string) []string {
+	orgIdsMap := make(map[string]bool)
+	for _, orgId := range splitList(current) {
+		orgIdsMap[orgId] = true
+	}
+	for _, orgId := range splitList(adding) {
+		orgIdsMap[orgId] = true
+	}
+	for _, orgId := range splitList(removing) {
+		delete(orgIdsMap, orgId)
+	}
+	orgIds := []string{}
+	for orgId := range orgIdsMap {
+		orgIds = append(orgIds, orgId)
+	}
+	sort.Strings(orgIds)
+	return orgIds
+}
+
+func splitList(values []string) []string {
+	orgIds := []string{}
+	for _, value := range values {
+		for _, orgId := range strings.Split(value, ",") {
+			orgId = strings.TrimSpace(orgId)
+			if orgId != "" {
+				orgIds = append(orgIds, orgId)
+			}
+		}
 	}
+	return orgIds
 }
@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versiondata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsolidateVersionDataInheritanceConfig(t *testing.T) {
+	current := VersionDataInheritanceConfig{
+		InheritingFromOrgs: []string{"a", "b"},
+		PublishingToOrgs:   []string{"a,c"},
+		InheritWorkloads:   map[string][]string{"a": {"w1"}},
+		PublishWorkloads:   map[string][]string{"a": {"w2"}, "c": {"w1"}},
+	}
+	tests := []struct {
+		name     string
+		desired  VersionDataInheritanceConfig
+		removed  VersionDataInheritanceConfig
+		expected VersionDataInheritanceConfig
+	}{
+		{
+			name:     "unchanged",
+			expected: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a", "b"}, PublishingToOrgs: []string{"a", "c"}, InheritWorkloads: map[string][]string{"a": {"w1"}}, PublishWorkloads: map[string][]string{"a": {"w2"}, "c": {"w1"}}},
+		},
+		{
+			name:    "add",
+			desired: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"d"}, InheritWorkloads: map[string][]string{"d": {"w2", "w1", "w2"}}},
+			expected: VersionDataInheritanceConfig{
+				InheritingFromOrgs: []string{"a", "b", "d"},
+				PublishingToOrgs:   []string{"a", "c"},
+				InheritWorkloads:   map[string][]string{"a": {"w1"}, "d": {"w1", "w2"}},
+				PublishWorkloads:   map[string][]string{"a": {"w2"}, "c": {"w1"}},
+			},
+		},
+		{
+			name:    "share all workloads again",
+			desired: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a"}},
+			expected: VersionDataInheritanceConfig{
+				InheritingFromOrgs: []string{"a", "b"},
+				PublishingToOrgs:   []string{"a", "c"},
+				PublishWorkloads:   map[string][]string{"a": {"w2"}, "c": {"w1"}},
+			},
+		},
+		{
+			name:    "remove from both lists",
+			removed: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a"}, PublishingToOrgs: []string{"a"}},
+			expected: VersionDataInheritanceConfig{
+				InheritingFromOrgs: []string{"b"},
+				PublishingToOrgs:   []string{"c"},
+				PublishWorkloads:   map[string][]string{"c": {"w1"}},
+			},
+		},
+		{
+			name:     "remove all",
+			removed:  VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a,b"}, PublishingToOrgs: []string{"a", "c"}},
+			expected: VersionDataInheritanceConfig{InheritingFromOrgs: []string{}, PublishingToOrgs: []string{}},
+		},
+		{
+			// removing an organization that isn't listed is a no-op
+			name:     "remove unlisted",
+			removed:  VersionDataInheritanceConfig{InheritingFromOrgs: []string{"c"}, PublishingToOrgs: []string{"b", "x"}},
+			expected: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a", "b"}, PublishingToOrgs: []string{"a", "c"}, InheritWorkloads: map[string][]string{"a": {"w1"}}, PublishWorkloads: map[string][]string{"a": {"w2"}, "c": {"w1"}}},
+		},
+		{
+			name:     "removal wins over adding",
+			desired:  VersionDataInheritanceConfig{PublishingToOrgs: []string{"d"}},
+			removed:  VersionDataInheritanceConfig{PublishingToOrgs: []string{"d"}},
+			expected: VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a", "b"}, PublishingToOrgs: []string{"a", "c"}, InheritWorkloads: map[string][]string{"a": {"w1"}}, PublishWorkloads: map[string][]string{"a": {"w2"}, "c": {"w1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consolidated := ConsolidateVersionDataInheritanceConfig(current, tt.desired, tt.removed)
+			if !reflect.DeepEqual(consolidated, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, consolidated)
+			}
+		})
+	}
+}
+
+func TestParseOrgEntries(t *testing.T) {
+	tests := []struct {
+		name              string
+		entries           []string
+		expectedOrgIds    []string
+		expectedWorkloads map[string][]string
+		expectedError     bool
+	}{
+		{
+			name:           "plain lists",
+			entries:        []string{"a, b", "c"},
+			expectedOrgIds: []string{"a", "b", "c"},
+		},
+		{
+			name:              "workload filters",
+			entries:           []string{"a:w1, w2", "b", "c:w3"},
+			expectedOrgIds:    []string{"a", "b", "c"},
+			expectedWorkloads: map[string][]string{"a": {"w1", "w2"}, "c": {"w3"}},
+		},
+		{
+			name:              "repeated organization",
+			entries:           []string{"a:w1", "a:w2"},
+			expectedOrgIds:    []string{"a", "a"},
+			expectedWorkloads: map[string][]string{"a": {"w1", "w2"}},
+		},
+		{
+			name:           "empty",
+			entries:        []string{},
+			expectedOrgIds: []string{},
+		},
+		{
+			name:          "filter for several organizations",
+			entries:       []string{"a,b:w1"},
+			expectedError: true,
+		},
+		{
+			name:          "missing organization",
+			entries:       []string{":w1"},
+			expectedError: true,
+		},
+		{
+			name:          "empty filter",
+			entries:       []string{"a: ,"},
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgIds, workloads, err := ParseOrgEntries(tt.entries)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("expected an error, got %v %v", orgIds, workloads)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(orgIds, tt.expectedOrgIds) || !reflect.DeepEqual(workloads, tt.expectedWorkloads) {
+				t.Errorf("expected %v %v, got %v %v", tt.expectedOrgIds, tt.expectedWorkloads, orgIds, workloads)
+			}
+		})
+	}
+}
+
+func TestNewRemovedVersionDataInheritanceConfigFromEntries(t *testing.T) {
+	config, err := NewRemovedVersionDataInheritanceConfigFromEntries([]string{"a,b"}, []string{"c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := VersionDataInheritanceConfig{InheritingFromOrgs: []string{"a", "b"}, PublishingToOrgs: []string{"c"}}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+	for _, entries := range [][]string{{"a:w1"}, {"a", "b:w1,w2"}} {
+		if _, err := NewRemovedVersionDataInheritanceConfigFromEntries(nil, entries); err == nil {
+			t.Errorf("expected removing %v to fail", entries)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versions
+
+import (
+	"fmt"
+	"regexp"
+
+	semver "github.com/Masterminds/semver/v3"
+)
+
+var minorVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// ValidateMaxVersion checks that a maximum version names a minor version stream, e.g. 4.15.
+func ValidateMaxVersion(maxVersion string) error {
+	if !minorVersionPattern.MatchString(maxVersion) {
+		return fmt.Errorf("max-version %q must be a minor version like 4.15", maxVersion)
+	}
+	return nil
+}
+
+// ExceedsMaxVersion returns true if the version is beyond the minor version stream of maxVersion.
+// An empty maxVersion doesn't restrict any version.
+func ExceedsMaxVersion(version string, maxVersion string) bool {
+	if maxVersion == "" {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	max, err := semver.NewVersion(maxVersion)
+	if err != nil {
+		return false
+	}
+	if v.Major() != max.Major() {
+		return v.Major() > max.Major()
+	}
+	return v.Minor() > max.Minor()
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2023 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versions
+
+import "testing"
+
+func TestValidateMaxVersion(t *testing.T) {
+	tests := []struct {
+		maxVersion    string
+		expectedError bool
+	}{
+		{maxVersion: "4.15"},
+		{maxVersion: "10.0"},
+		{maxVersion: "4", expectedError: true},
+		{maxVersion: "4.15.1", expectedError: true},
+		{maxVersion: "4.x", expectedError: true},
+		{maxVersion: "v4.15", expectedError: true},
+		{maxVersion: "", expectedError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.maxVersion, func(t *testing.T) {
+			err := ValidateMaxVersion(tt.maxVersion)
+			if tt.expectedError && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExceedsMaxVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		maxVersion string
+		expected   bool
+	}{
+		{version: "4.15.0", maxVersion: "", expected: false},
+		{version: "4.14.9", maxVersion: "4.15", expected: false},
+		{version: "4.15.30", maxVersion: "4.15", expected: false},
+		{version: "4.16.0", maxVersion: "4.15", expected: true},
+		{version: "4.16.0-rc.1", maxVersion: "4.15", expected: true},
+		{version: "5.0.0", maxVersion: "4.15", expected: true},
+		{version: "3.11.0", maxVersion: "4.15", expected: false},
+		// patch versions restrict upgrades to their minor version stream
+		{version: "4.14.3", maxVersion: "4.14.1", expected: false},
+		{version: "4.15.0", maxVersion: "4.14.1", expected: true},
+		{version: "invalid", maxVersion: "4.15", expected: false},
+		{version: "4.16.0", maxVersion: "invalid", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+"/"+tt.maxVersion, func(t *testing.T) {
+			if got := ExceedsMaxVersion(tt.version, tt.maxVersion); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}